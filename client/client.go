@@ -1,46 +1,498 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
+	"github.com/go-ldap/ldap/v3/gssapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	krbclient "github.com/jcmturner/gokrb5/v8/client"
+)
+
+const (
+	BindMethodSimple          = "simple"
+	BindMethodExternal        = "external"
+	BindMethodAnonymous       = "anonymous"
+	BindMethodUnauthenticated = "unauthenticated"
+	BindMethodNTLM            = "ntlm"
+	BindMethodGSSAPI          = "gssapi"
+)
+
+// BindMethods lists the supported values for Client.BindMethod.
+var BindMethods = []string{
+	BindMethodSimple,
+	BindMethodExternal,
+	BindMethodAnonymous,
+	BindMethodUnauthenticated,
+	BindMethodNTLM,
+	BindMethodGSSAPI,
+}
+
+// Strategies of UpdateEntry for modifying changed attributes.
+const (
+	ModifyStrategyReplace = "replace"
+	ModifyStrategyValues  = "values"
 )
 
 type Client struct {
-	Conn         *ldap.Conn
-	Host         string
-	Port         int
-	BindUser     string
-	BindPassword string
-	TLS          bool
-	TLSInsecure  bool
+	URLs                     []string
+	RandomizeURLs            bool
+	WriteURL                 string
+	Host                     string
+	Port                     int
+	BindMethod               string
+	BindUser                 string
+	BindPassword             string
+	BindPasswordFile         string
+	BindPasswordCommand      []string
+	NTLMDomain               string
+	NTLMHash                 string
+	KerberosRealm            string
+	KerberosKeytab           string
+	KerberosCCache           string
+	KerberosConfig           string
+	KerberosSPN              string
+	TLS                      bool
+	StartTLS                 bool
+	TLSInsecure              bool
+	TLSCACertificate         string
+	TLSCACertificateFile     string
+	TLSClientCertificate     string
+	TLSClientCertificateFile string
+	TLSClientKey             string
+	TLSClientKeyFile         string
+	TLSServerName            string
+	TLSMinVersion            uint16
+	MaxRetries               int
+	RetryBackoff             time.Duration
+	MaxConnections           int
+	DialTimeout              time.Duration
+	RequestTimeout           time.Duration
+	SearchTimeLimit          int
+	SearchSizeLimit          int
+	ProxiedAuthz             string
+	ProtectedDNs             []string
+	DeletedContainer         string
+	// EntryAttributeNamesCaseSensitive is used for comparing attribute names if the schema of the server is not available
+	EntryAttributeNamesCaseSensitive bool
+
+	mutex     sync.Mutex
+	readPool  *pool
+	writePool *pool
+
+	schemaMutex sync.Mutex
+	schema      *Schema
+	schemaErr   error
+}
+
+// pool returns the pool for reads or for writes.
+// Reads are sent to the first server of URLs which can be bound to.
+// Writes prefer WriteURL and fall back to URLs, if WriteURL is not set
+// reads and writes share the same pool.
+// The pools are created by the first operation.
+func (c *Client) pool(write bool) *pool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.readPool == nil {
+		c.readPool = newPool(c.MaxConnections, func(ctx context.Context) (*ldap.Conn, error) {
+			return c.connectAny(ctx, c.urls())
+		})
+		c.writePool = c.readPool
+		if c.WriteURL != "" {
+			c.writePool = newPool(c.MaxConnections, func(ctx context.Context) (*ldap.Conn, error) {
+				return c.connectAny(ctx, c.writeURLs(c.urls()))
+			})
+		}
+	}
+
+	if write {
+		return c.writePool
+	}
+	return c.readPool
+}
+
+// validate checks the settings required for connecting.
+func (c *Client) validate() error {
+	if len(c.URLs) == 0 && (c.Host == "" || c.Port == 0) {
+		return fmt.Errorf("either URLs or host and port are required")
+	}
+	switch c.BindMethod {
+	case BindMethodSimple, "":
+		if c.BindUser == "" || !c.hasBindPassword() {
+			return fmt.Errorf("bind user and bind password, bind password file or bind password command are required for bind method '%s'", BindMethodSimple)
+		}
+	case BindMethodUnauthenticated:
+		if c.BindUser == "" {
+			return fmt.Errorf("bind user is required for bind method '%s'", c.BindMethod)
+		}
+	case BindMethodNTLM:
+		if c.BindUser == "" || (c.NTLMHash == "" && !c.hasBindPassword()) {
+			return fmt.Errorf("bind user and NTLM hash or bind password are required for bind method '%s'", c.BindMethod)
+		}
+	case BindMethodGSSAPI:
+		if c.KerberosCCache == "" && (c.BindUser == "" || c.KerberosRealm == "" || (c.KerberosKeytab == "" && !c.hasBindPassword())) {
+			return fmt.Errorf("Kerberos credential cache or bind user, Kerberos realm and Kerberos keytab or bind password are required for bind method '%s'", c.BindMethod)
+		}
+	}
+	return nil
+}
+
+// writeURLs returns WriteURL followed by the other urls.
+func (c *Client) writeURLs(urls []string) []string {
+	writeURLs := []string{c.WriteURL}
+	for _, u := range urls {
+		if u != c.WriteURL {
+			writeURLs = append(writeURLs, u)
+		}
+	}
+	return writeURLs
+}
+
+// do runs op on a connection of the read or the write pool.
+// If the connection was lost, it is dropped from the pool
+// and, if retry is set or the request of op wasn't sent at all, op is run again on another
+// connection up to MaxRetries times, waiting RetryBackoff before the first retry and doubling
+// the wait for each further retry.
+// If ctx is done, the connection is closed to abort op.
+func (c *Client) do(ctx context.Context, write bool, retry bool, op func(conn *ldap.Conn) error) (err error) {
+	p := c.pool(write)
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(c.RetryBackoff << (attempt - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			tflog.Info(ctx, "retrying LDAP operation", map[string]interface{}{"attempt": attempt, "max_retries": c.MaxRetries})
+		}
+		var conn *ldap.Conn
+		conn, err = p.get(ctx)
+		if isConnectionError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		stop := context.AfterFunc(ctx, func() {
+			conn.Close()
+		})
+		err = op(conn)
+		if !stop() {
+			p.put(conn)
+			return fmt.Errorf("LDAP operation aborted: %w", ctx.Err())
+		}
+		if isConnectionError(err) {
+			tflog.Warn(ctx, "lost connection to LDAP server", map[string]interface{}{"error": err.Error()})
+			conn.Close()
+		}
+		p.put(conn)
+		if !isConnectionError(err) || (!retry && !isUnsentError(err)) {
+			return err
+		}
+	}
+	return err
 }
 
-func (c *Client) Connect() error {
-	uri := fmt.Sprintf("%s:%d", c.Host, c.Port)
+// isUnsentError reports whether err means that the request never reached the server, because the
+// connection was already closed, e.g. by the server after an idle timeout, or writing the request failed.
+// Such a request can be sent again even if it must not be repeated.
+func isUnsentError(err error) bool {
+	if ldap.IsErrorWithCode(err, ldap.ErrorNetwork) && strings.Contains(err.Error(), "ldap: connection closed") {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "unable to send request")
+}
 
-	if c.TLS {
-		conn, err := ldap.DialTLS("tcp", uri, &tls.Config{
-			InsecureSkipVerify: c.TLSInsecure,
+// connectionLossMessages are the messages of the plain errors go-ldap returns for requests
+// in progress when the connection is lost.
+var connectionLossMessages = []string{"unable to read LDAP response packet", "unable to send request"}
+
+// isConnectionError reports whether err means that the connection to the server was lost or couldn't be established.
+func isConnectionError(err error) bool {
+	if ldap.IsErrorAnyOf(err, ldap.ErrorNetwork, ldap.LDAPResultServerDown, ldap.LDAPResultConnectError) {
+		return true
+	}
+	for _, message := range connectionLossMessages {
+		if err != nil && strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+	return false
+}
+
+// urls returns a copy of URLs, shuffled if RandomizeURLs is set,
+// or, if URLs is not set, the URL built from Host, Port and TLS.
+func (c *Client) urls() []string {
+	if len(c.URLs) == 0 {
+		scheme := "ldap"
+		if c.TLS {
+			scheme = "ldaps"
+		}
+		return []string{scheme + "://" + net.JoinHostPort(c.Host, strconv.Itoa(c.Port))}
+	}
+	urls := append([]string{}, c.URLs...)
+	if c.RandomizeURLs {
+		rand.Shuffle(len(urls), func(i, j int) {
+			urls[i], urls[j] = urls[j], urls[i]
 		})
+	}
+	return urls
+}
+
+// connectAny returns a bound connection to the first of urls that succeeds.
+func (c *Client) connectAny(ctx context.Context, urls []string) (*ldap.Conn, error) {
+	err := c.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, rawURL := range urls {
+		conn, err := c.connect(ctx, rawURL)
 		if err != nil {
-			return fmt.Errorf("error dialing: %s", err)
+			tflog.Warn(ctx, "error connecting to LDAP server", map[string]interface{}{"url": rawURL, "error": err.Error()})
+			errs = append(errs, fmt.Errorf("%s: %w", rawURL, err))
+			continue
 		}
-		c.Conn = conn
+		tflog.Info(ctx, "connected to LDAP server", map[string]interface{}{"url": rawURL})
+		return conn, nil
+	}
+	return nil, errors.Join(errs...)
+}
 
-	} else {
-		conn, err := ldap.Dial("tcp", uri)
+// connect dials rawURL, starts TLS if configured and binds.
+// DialTimeout limits dialing, RequestTimeout limits each request on the connection.
+func (c *Client) connect(ctx context.Context, rawURL string) (*ldap.Conn, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if u.Scheme == "ldaps" || c.StartTLS {
+		tlsConfig, err = c.tlsConfig(u.Hostname())
 		if err != nil {
-			return fmt.Errorf("error dialing: %s", err)
+			return nil, err
 		}
-		c.Conn = conn
 	}
 
-	err := c.Conn.Bind(c.BindUser, c.BindPassword)
+	dialer := &net.Dialer{Timeout: ldap.DefaultTimeout}
+	if c.DialTimeout > 0 {
+		dialer.Timeout = c.DialTimeout
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.Deadline = deadline
+	}
+	conn, err := ldap.DialURL(u.String(), ldap.DialWithDialer(dialer), ldap.DialWithTLSConfig(tlsConfig))
 	if err != nil {
-		return fmt.Errorf("error binding: %s", err)
+		return nil, fmt.Errorf("error dialing: %w", err)
+	}
+	if c.RequestTimeout > 0 {
+		conn.SetTimeout(c.RequestTimeout)
 	}
 
-	return nil
+	if c.StartTLS && u.Scheme != "ldaps" {
+		err = conn.StartTLS(tlsConfig)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+
+	err = c.bind(conn, u.Hostname())
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error binding: %w", err)
+	}
+
+	return conn, nil
+}
+
+// parseURL parses an LDAP URL.
+// For ldapi the socket path may be given percent-encoded as host
+// (ldapi://%2Fvar%2Frun%2Fslapd%2Fldapi), which is rewritten to the path form
+// understood by ldap.DialURL.
+func parseURL(rawURL string) (*url.URL, error) {
+	const ldapiPrefix = "ldapi://"
+	if len(rawURL) > len(ldapiPrefix) && strings.EqualFold(rawURL[:len(ldapiPrefix)], ldapiPrefix) {
+		host, _, _ := strings.Cut(rawURL[len(ldapiPrefix):], "/")
+		if host != "" {
+			socketPath, err := url.PathUnescape(host)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing URL '%s': %s", rawURL, err)
+			}
+			return &url.URL{Scheme: "ldapi", Path: socketPath}, nil
+		}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing URL '%s': %s", rawURL, err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	switch u.Scheme {
+	case "ldap", "ldaps", "ldapi":
+		return u, nil
+	}
+	return nil, fmt.Errorf("unsupported scheme '%s' in URL '%s'", u.Scheme, rawURL)
+}
+
+// bind authenticates the connection to host according to BindMethod,
+// an empty BindMethod is handled as BindMethodSimple.
+func (c *Client) bind(conn *ldap.Conn, host string) error {
+	switch c.BindMethod {
+	case BindMethodSimple, "":
+		password, err := c.bindPassword()
+		if err != nil {
+			return err
+		}
+		return conn.Bind(c.BindUser, password)
+	case BindMethodExternal:
+		return conn.ExternalBind()
+	case BindMethodAnonymous:
+		_, err := conn.SimpleBind(&ldap.SimpleBindRequest{
+			AllowEmptyPassword: true,
+		})
+		return err
+	case BindMethodUnauthenticated:
+		return conn.UnauthenticatedBind(c.BindUser)
+	case BindMethodNTLM:
+		if c.NTLMHash != "" {
+			return conn.NTLMBindWithHash(c.NTLMDomain, c.BindUser, c.NTLMHash)
+		}
+		password, err := c.bindPassword()
+		if err != nil {
+			return err
+		}
+		return conn.NTLMBind(c.NTLMDomain, c.BindUser, password)
+	case BindMethodGSSAPI:
+		return c.gssapiBind(conn, host)
+	}
+	return fmt.Errorf("unsupported bind method '%s'", c.BindMethod)
+}
+
+// gssapiBind performs a SASL GSSAPI (Kerberos) bind with the credential cache KerberosCCache,
+// the keytab KerberosKeytab or the bind password. The service principal defaults to ldap/host.
+func (c *Client) gssapiBind(conn *ldap.Conn, host string) error {
+	// FAST pre-authentication is not supported by Active Directory
+	settings := krbclient.DisablePAFXFAST(true)
+
+	var kerberosClient *gssapi.Client
+	var err error
+	switch {
+	case c.KerberosCCache != "":
+		kerberosClient, err = gssapi.NewClientFromCCache(c.KerberosCCache, c.KerberosConfig, settings)
+	case c.KerberosKeytab != "":
+		kerberosClient, err = gssapi.NewClientWithKeytab(c.BindUser, c.KerberosRealm, c.KerberosKeytab, c.KerberosConfig, settings)
+	default:
+		var password string
+		password, err = c.bindPassword()
+		if err != nil {
+			return err
+		}
+		kerberosClient, err = gssapi.NewClientWithPassword(c.BindUser, c.KerberosRealm, password, c.KerberosConfig, settings)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating Kerberos client: %s", err)
+	}
+	defer kerberosClient.Close()
+
+	servicePrincipal := c.KerberosSPN
+	if servicePrincipal == "" {
+		servicePrincipal = "ldap/" + host
+	}
+	return conn.GSSAPIBind(kerberosClient, servicePrincipal, "")
+}
+
+// hasBindPassword reports whether BindPassword, BindPasswordFile or BindPasswordCommand is set.
+func (c *Client) hasBindPassword() bool {
+	return c.BindPassword != "" || c.BindPasswordFile != "" || len(c.BindPasswordCommand) > 0
+}
+
+// bindPassword returns the output of BindPasswordCommand, the content of BindPasswordFile or BindPassword.
+// Command and file are evaluated for each bind, so that a rotated password is picked up by reconnects.
+func (c *Client) bindPassword() (string, error) {
+	if len(c.BindPasswordCommand) > 0 {
+		output, err := exec.Command(c.BindPasswordCommand[0], c.BindPasswordCommand[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("error running bind password command '%s': %s", c.BindPasswordCommand[0], err)
+		}
+		return strings.TrimRight(string(output), "\r\n"), nil
+	}
+	if c.BindPasswordFile != "" {
+		content, err := os.ReadFile(c.BindPasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading bind password file: %s", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+	return c.BindPassword, nil
+}
+
+// tlsConfig returns the TLS settings shared by LDAPS and StartTLS connections.
+func (c *Client) tlsConfig(serverName string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.TLSInsecure,
+		ServerName:         serverName,
+		MinVersion:         c.TLSMinVersion,
+	}
+	if c.TLSServerName != "" {
+		tlsConfig.ServerName = c.TLSServerName
+	}
+
+	caCertificate, err := valueOrFileContent(c.TLSCACertificate, c.TLSCACertificateFile)
+	if err != nil {
+		return nil, err
+	}
+	if caCertificate != "" {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM([]byte(caCertificate)) {
+			return nil, fmt.Errorf("error parsing CA certificate: no PEM encoded certificate found")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	clientCertificate, err := valueOrFileContent(c.TLSClientCertificate, c.TLSClientCertificateFile)
+	if err != nil {
+		return nil, err
+	}
+	clientKey, err := valueOrFileContent(c.TLSClientKey, c.TLSClientKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if clientCertificate != "" || clientKey != "" {
+		certificate, err := tls.X509KeyPair([]byte(clientCertificate), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// valueOrFileContent returns value or, if value is empty, the content of the file fileName.
+func valueOrFileContent(value string, fileName string) (string, error) {
+	if value != "" || fileName == "" {
+		return value, nil
+	}
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", fmt.Errorf("error reading '%s': %s", fileName, err)
+	}
+	return string(content), nil
 }
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestDoRetriesDialFailures(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	c := &Client{
+		URLs:         []string{"ldap://" + address},
+		BindUser:     "cn=admin,dc=example,dc=com",
+		BindPassword: "admin",
+		MaxRetries:   2,
+		RetryBackoff: 20 * time.Millisecond,
+		DialTimeout:  time.Second,
+	}
+	start := time.Now()
+	err = c.do(context.Background(), false, true, func(conn *ldap.Conn) error {
+		return nil
+	})
+	if !isConnectionError(err) {
+		t.Fatalf("expected a connection error, got %v", err)
+	}
+	// the retries wait 20ms and 40ms
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("expected the dial to be retried with backoff, returned after %s", elapsed)
+	}
+}
+
+func TestDoRetriesUnsentRequests(t *testing.T) {
+	server := newTestServer(t, func(connection int, op *ber.Packet, controls []ldap.Control) []testResponse {
+		return []testResponse{{op: testResult(ldap.ApplicationModifyResponse, ldap.LDAPResultSuccess)}}
+	})
+	c := &Client{
+		URLs:         []string{"ldap://" + server.address},
+		BindUser:     "cn=admin,dc=example,dc=com",
+		BindPassword: "admin",
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+	}
+
+	attempts := 0
+	err := c.do(context.Background(), true, false, func(conn *ldap.Conn) error {
+		attempts++
+		if attempts == 1 {
+			// the connection was closed, e.g. by the server after an idle timeout, before the request was sent
+			conn.Close()
+		}
+		modifyRequest := ldap.NewModifyRequest("cn=a,dc=example,dc=com", nil)
+		modifyRequest.Add("description", []string{"a"})
+		return conn.Modify(modifyRequest)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 || server.requestCount(ldap.ApplicationModifyRequest) != 1 {
+		t.Errorf("expected the unsent modification to be sent once on a second attempt, got %d attempts and %d modifications", attempts, server.requestCount(ldap.ApplicationModifyRequest))
+	}
+}
+
+func TestDoDoesNotRepeatSentRequests(t *testing.T) {
+	// the connection is lost after the server received the modification
+	server := newTestServer(t, func(connection int, op *ber.Packet, controls []ldap.Control) []testResponse {
+		return nil
+	})
+	c := &Client{
+		URLs:         []string{"ldap://" + server.address},
+		BindUser:     "cn=admin,dc=example,dc=com",
+		BindPassword: "admin",
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+	}
+
+	err := c.do(context.Background(), true, false, func(conn *ldap.Conn) error {
+		modifyRequest := ldap.NewModifyRequest("cn=a,dc=example,dc=com", nil)
+		modifyRequest.Add("description", []string{"a"})
+		return conn.Modify(modifyRequest)
+	})
+	if !isConnectionError(err) {
+		t.Fatalf("expected a connection error, got %v", err)
+	}
+	if count := server.requestCount(ldap.ApplicationModifyRequest); count != 1 {
+		t.Errorf("expected the modification not to be repeated, got %d modifications", count)
+	}
+}
+
+func TestSchemaKeepsNoTransientErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	c := &Client{
+		URLs:         []string{"ldap://" + address},
+		BindUser:     "cn=admin,dc=example,dc=com",
+		BindPassword: "admin",
+		DialTimeout:  time.Second,
+	}
+	_, err = c.Schema(context.Background())
+	if err == nil {
+		t.Fatal("expected reading the schema to fail")
+	}
+	if c.schemaErr != nil {
+		t.Errorf("expected the connection error not to be kept, got %v", c.schemaErr)
+	}
+}
+
+func TestBindPassword(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("file secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		client   *Client
+		expected string
+		err      bool
+	}{
+		{"plain", &Client{BindPassword: "secret"}, "secret", false},
+		{"file", &Client{BindPassword: "secret", BindPasswordFile: passwordFile}, "file secret", false},
+		{"command", &Client{BindPasswordCommand: []string{"printf", "command secret\\r\\n"}}, "command secret", false},
+		{"command before file", &Client{BindPasswordFile: passwordFile, BindPasswordCommand: []string{"printf", "command secret"}}, "command secret", false},
+		{"missing file", &Client{BindPasswordFile: filepath.Join(t.TempDir(), "missing")}, "", true},
+		{"failing command", &Client{BindPasswordCommand: []string{"false"}}, "", true},
+	}
+	for _, test := range tests {
+		password, err := test.client.bindPassword()
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if password != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, password)
+		}
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		rawURL   string
+		expected string
+		err      bool
+	}{
+		{rawURL: "ldap://ldap.example.com", expected: "ldap://ldap.example.com"},
+		{rawURL: "ldaps://ldap.example.com:636", expected: "ldaps://ldap.example.com:636"},
+		{rawURL: "LDAPS://ldap.example.com", expected: "ldaps://ldap.example.com"},
+		{rawURL: "ldap://[::1]:389", expected: "ldap://[::1]:389"},
+		{rawURL: "ldapi://%2Fvar%2Frun%2Fslapd%2Fldapi", expected: "ldapi:///var/run/slapd/ldapi"},
+		{rawURL: "LDAPI://%2Fvar%2Frun%2Fslapd%2Fldapi/", expected: "ldapi:///var/run/slapd/ldapi"},
+		{rawURL: "ldapi:///var/run/slapd/ldapi", expected: "ldapi:///var/run/slapd/ldapi"},
+		{rawURL: "ldapi://%2Fvar%2Frun%2", err: true},
+		{rawURL: "http://ldap.example.com", err: true},
+		{rawURL: "ldap://ldap.example.com:port", err: true},
+	}
+	for _, test := range tests {
+		u, err := parseURL(test.rawURL)
+		if test.err {
+			if err == nil {
+				t.Errorf("parseURL(%q): expected an error, got %s", test.rawURL, u)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseURL(%q): %s", test.rawURL, err)
+			continue
+		}
+		if u.String() != test.expected {
+			t.Errorf("parseURL(%q): expected %q, got %q", test.rawURL, test.expected, u.String())
+		}
+	}
+}
+
+// testCertificate returns a PEM encoded self-signed certificate and its key.
+func testCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ldap.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateKey}))
+}
+
+func TestTLSConfig(t *testing.T) {
+	certificate, key := testCertificate(t)
+	certificateFile := filepath.Join(t.TempDir(), "certificate.pem")
+	if err := os.WriteFile(certificateFile, []byte(certificate), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		client             *Client
+		serverName         string
+		insecure           bool
+		rootCAs            bool
+		clientCertificates int
+		err                bool
+	}{
+		{name: "default", client: &Client{}, serverName: "ldap.example.com"},
+		{name: "server name", client: &Client{TLSServerName: "ldap.internal"}, serverName: "ldap.internal"},
+		{name: "insecure", client: &Client{TLSInsecure: true}, serverName: "ldap.example.com", insecure: true},
+		{name: "insecure with server name", client: &Client{TLSInsecure: true, TLSServerName: "ldap.internal"}, serverName: "ldap.internal", insecure: true},
+		{name: "CA", client: &Client{TLSCACertificate: certificate}, serverName: "ldap.example.com", rootCAs: true},
+		{name: "CA file", client: &Client{TLSCACertificateFile: certificateFile}, serverName: "ldap.example.com", rootCAs: true},
+		{name: "CA before CA file", client: &Client{TLSCACertificate: certificate, TLSCACertificateFile: filepath.Join(t.TempDir(), "missing")}, serverName: "ldap.example.com", rootCAs: true},
+		{name: "invalid CA", client: &Client{TLSCACertificate: "no certificate"}, err: true},
+		{name: "missing CA file", client: &Client{TLSCACertificateFile: filepath.Join(t.TempDir(), "missing")}, err: true},
+		{name: "client certificate", client: &Client{TLSClientCertificate: certificate, TLSClientKey: key}, serverName: "ldap.example.com", clientCertificates: 1},
+		{name: "client certificate without key", client: &Client{TLSClientCertificate: certificate}, err: true},
+	}
+	for _, test := range tests {
+		tlsConfig, err := test.client.tlsConfig("ldap.example.com")
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if tlsConfig.ServerName != test.serverName {
+			t.Errorf("%s: expected server name %q, got %q", test.name, test.serverName, tlsConfig.ServerName)
+		}
+		if tlsConfig.InsecureSkipVerify != test.insecure {
+			t.Errorf("%s: expected InsecureSkipVerify %t, got %t", test.name, test.insecure, tlsConfig.InsecureSkipVerify)
+		}
+		if (tlsConfig.RootCAs != nil) != test.rootCAs {
+			t.Errorf("%s: expected root CAs %t, got %v", test.name, test.rootCAs, tlsConfig.RootCAs)
+		}
+		if len(tlsConfig.Certificates) != test.clientCertificates {
+			t.Errorf("%s: expected %d client certificates, got %d", test.name, test.clientCertificates, len(tlsConfig.Certificates))
+		}
+	}
+}
@@ -10,6 +10,10 @@ type IgnoreAndBase64Encode struct {
 	IgnoreAttributePatterns       *[]string
 	Base64encodeAttributes        *[]string
 	Base64encodeAttributePatterns *[]string
+	// Base64encodeAttributeFunc reports whether an attribute not in the lists is base64 encoded, e.g. because it is binary
+	Base64encodeAttributeFunc func(attributeName string) bool
+	// NoBase64encodeAttributes are not base64 encoded by Base64encodeAttributeFunc
+	NoBase64encodeAttributes *[]string
 }
 
 func NewIgnoreAndBase64Encode() *IgnoreAndBase64Encode {
@@ -18,5 +22,7 @@ func NewIgnoreAndBase64Encode() *IgnoreAndBase64Encode {
 		new([]string),
 		new([]string),
 		new([]string),
+		nil,
+		new([]string),
 	}
 }
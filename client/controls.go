@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ControlTypeProxiedAuthorization is the OID of the proxied authorization control (RFC 4370).
+const ControlTypeProxiedAuthorization = "2.16.840.1.113730.3.4.18"
+
+type proxiedAuthzKey struct{}
+
+// ContextWithProxiedAuthz returns a context overriding Client.ProxiedAuthz for
+// the operations called with it, an empty authzID keeps the provider setting.
+func ContextWithProxiedAuthz(ctx context.Context, authzID string) context.Context {
+	if authzID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, proxiedAuthzKey{}, authzID)
+}
+
+// proxiedAuthz returns the authorization identity for operations called with ctx.
+// A DN without the "dn:" or "u:" prefix of RFC 4513 is prefixed by "dn:".
+func (c *Client) proxiedAuthz(ctx context.Context) string {
+	authzID := c.ProxiedAuthz
+	if value, ok := ctx.Value(proxiedAuthzKey{}).(string); ok {
+		authzID = value
+	}
+	if authzID == "" || strings.HasPrefix(authzID, "dn:") || strings.HasPrefix(authzID, "u:") {
+		return authzID
+	}
+	return "dn:" + authzID
+}
+
+// controls returns the request controls for operations called with ctx.
+func (c *Client) controls(ctx context.Context) []ldap.Control {
+	controls := []ldap.Control{}
+	if authzID := c.proxiedAuthz(ctx); authzID != "" {
+		// the control is critical, the server must not fall back to the bind identity
+		controls = append(controls, ldap.NewControlString(ControlTypeProxiedAuthorization, true, authzID))
+	}
+	return controls
+}
+
+// ControlTypeAssertion is the OID of the assertion control (RFC 4528).
+const ControlTypeAssertion = "1.3.6.1.1.12"
+
+// RevisionAttributeNames lists the attributes changing with every modification of an entry:
+// entryCSN (OpenLDAP), modifyTimestamp (RFC 4512) and uSNChanged (Active Directory).
+var RevisionAttributeNames = []string{"entryCSN", "uSNChanged", "modifyTimestamp"}
+
+type assertionKey struct{}
+
+type assertion struct {
+	dn     string
+	filter string
+}
+
+// ContextWithAssertion returns a context for which modifications, renames and deletions of the entry dn
+// are only performed if the entry matches filter, an empty filter asserts nothing.
+func ContextWithAssertion(ctx context.Context, dn string, filter string) context.Context {
+	if filter == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, assertionKey{}, assertion{dn: dn, filter: filter})
+}
+
+// GetEntryRevision returns the revision of ldapEntry as filter item, e.g. `entryCSN=...`,
+// or "" if the entry has none of the RevisionAttributeNames.
+func GetEntryRevision(ldapEntry *LdapEntry) string {
+	for _, revisionAttributeName := range RevisionAttributeNames {
+		for attributeName, attributeValues := range ldapEntry.Entry {
+			if strings.EqualFold(attributeName, revisionAttributeName) && len(attributeValues) == 1 {
+				return revisionAttributeName + "=" + ldap.EscapeFilter(attributeValues[0])
+			}
+		}
+	}
+	return ""
+}
+
+// writeControls returns the request controls for modifications, renames and deletions of the entry dn
+// called with ctx.
+func (c *Client) writeControls(ctx context.Context, dn string) ([]ldap.Control, error) {
+	controls := c.controls(ctx)
+	if value, ok := ctx.Value(assertionKey{}).(assertion); ok && dnEqualFold(value.dn, dn) {
+		filter, err := ldap.CompileFilter(value.filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid assertion '%s': %w", value.filter, err)
+		}
+		// the value of the control is the BER encoded filter
+		controls = append(controls, ldap.NewControlString(ControlTypeAssertion, true, string(filter.Bytes())))
+	}
+	return controls, nil
+}
+
+// hasAssertion reports whether controls contain the assertion control.
+// Such a request must not be repeated once it may have been performed,
+// because the entry doesn't match the assertion anymore after it was changed.
+func hasAssertion(controls []ldap.Control) bool {
+	for _, control := range controls {
+		if control.GetControlType() == ControlTypeAssertion {
+			return true
+		}
+	}
+	return false
+}
+
+// assertionError explains the error of an operation on the entry dn, if it was caused by the assertion.
+func assertionError(dn string, err error) error {
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultAssertionFailed) {
+		return ldap.NewError(ldap.LDAPResultAssertionFailed, fmt.Errorf("the entry '%s' changed since plan, refresh and plan again: %w", dn, err))
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestProxiedAuthz(t *testing.T) {
+	tests := []struct {
+		providerAuthzID string
+		resourceAuthzID string
+		expected        string
+	}{
+		{"", "", ""},
+		{"cn=admin,dc=example,dc=com", "", "dn:cn=admin,dc=example,dc=com"},
+		{"dn:cn=admin,dc=example,dc=com", "", "dn:cn=admin,dc=example,dc=com"},
+		{"u:admin", "", "u:admin"},
+		{"u:admin", "cn=jim,dc=example,dc=com", "dn:cn=jim,dc=example,dc=com"},
+		{"", "u:jim", "u:jim"},
+	}
+	for _, test := range tests {
+		c := &Client{ProxiedAuthz: test.providerAuthzID}
+		ctx := ContextWithProxiedAuthz(context.Background(), test.resourceAuthzID)
+		if authzID := c.proxiedAuthz(ctx); authzID != test.expected {
+			t.Errorf("proxiedAuthz(%q, %q): expected %q, got %q", test.providerAuthzID, test.resourceAuthzID, test.expected, authzID)
+		}
+	}
+}
+
+func TestControlsProxiedAuthz(t *testing.T) {
+	c := &Client{}
+	if controls := c.controls(context.Background()); len(controls) != 0 {
+		t.Errorf("expected no controls without proxied authorization, got %v", controls)
+	}
+
+	c.ProxiedAuthz = "cn=admin,dc=example,dc=com"
+	controls := c.controls(context.Background())
+	if len(controls) != 1 {
+		t.Fatalf("expected the proxied authorization control, got %v", controls)
+	}
+	control, ok := controls[0].(*ldap.ControlString)
+	if !ok || control.ControlType != ControlTypeProxiedAuthorization || !control.Criticality || control.ControlValue != "dn:cn=admin,dc=example,dc=com" {
+		t.Errorf("expected the critical proxied authorization control for 'dn:cn=admin,dc=example,dc=com', got %v", controls[0])
+	}
+}
+
+func TestWriteControlsAssertion(t *testing.T) {
+	c := &Client{}
+	ctx := ContextWithAssertion(context.Background(), "uid=jim,ou=Users,dc=example,dc=com", "(entryCSN=20240101000000.000000Z#000000#000#000000)")
+	tests := []struct {
+		dn        string
+		assertion bool
+	}{
+		{"uid=jim,ou=Users,dc=example,dc=com", true},
+		{"UID=Jim, OU=users,DC=Example,DC=com", true},
+		{"uid=jim2,ou=users,dc=example,dc=com", false},
+		{"ou=users,dc=example,dc=com", false},
+	}
+	for _, test := range tests {
+		controls, err := c.writeControls(ctx, test.dn)
+		if err != nil {
+			t.Errorf("writeControls(%q): %s", test.dn, err)
+			continue
+		}
+		if hasAssertion(controls) != test.assertion {
+			t.Errorf("writeControls(%q): expected the assertion control %t, got %v", test.dn, test.assertion, controls)
+		}
+	}
+
+	if controls, err := c.writeControls(ContextWithAssertion(context.Background(), "uid=jim,dc=example,dc=com", ""), "uid=jim,dc=example,dc=com"); err != nil || hasAssertion(controls) {
+		t.Errorf("expected no assertion control for an empty filter, got %v, %v", controls, err)
+	}
+	if _, err := c.writeControls(ContextWithAssertion(context.Background(), "uid=jim,dc=example,dc=com", "(entryCSN="), "uid=jim,dc=example,dc=com"); err == nil {
+		t.Error("expected an error for an invalid assertion")
+	}
+}
+
+func TestAssertionError(t *testing.T) {
+	err := assertionError("uid=jim,dc=example,dc=com", ldap.NewError(ldap.LDAPResultAssertionFailed, errors.New("assertion failed")))
+	if !ldap.IsErrorWithCode(err, ldap.LDAPResultAssertionFailed) || !strings.Contains(err.Error(), "the entry 'uid=jim,dc=example,dc=com' changed since plan") {
+		t.Errorf("expected the assertion failure to be explained, got %v", err)
+	}
+
+	otherErr := ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))
+	if err := assertionError("uid=jim,dc=example,dc=com", otherErr); err != otherErr {
+		t.Errorf("expected other errors to be kept, got %v", err)
+	}
+	if err := assertionError("uid=jim,dc=example,dc=com", nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
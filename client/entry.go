@@ -1,13 +1,17 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"github.com/go-ldap/ldap/v3"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"log"
+	"strings"
+	"time"
 )
 
 func (c *Client) ReadEntryByFilter(
+	ctx context.Context,
 	baseDn string,
 	filter string,
 	attributes *[]string,
@@ -16,16 +20,19 @@ func (c *Client) ReadEntryByFilter(
 		baseDn,
 		ldap.ScopeWholeSubtree,
 		ldap.NeverDerefAliases,
-		0,
-		0,
+		c.SearchSizeLimit,
+		c.SearchTimeLimit,
 		false,
 		filter,
 		*attributes,
-		[]ldap.Control{},
+		c.controls(ctx),
 	)
 
-	searchResult, err := c.Conn.Search(req)
-
+	var searchResult *ldap.SearchResult
+	err = c.do(ctx, false, true, func(conn *ldap.Conn) (err error) {
+		searchResult, err = conn.Search(req)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -51,34 +58,59 @@ func (c *Client) ReadEntryByFilter(
 }
 
 func (c *Client) ReadEntriesByFilter(
+	ctx context.Context,
 	baseDn string,
 	filter string,
 	attributes *[]string,
 	pagingSize int,
+	scope int,
 ) (ldapEntries *[]LdapEntry, err error) {
-	req := ldap.NewSearchRequest(
-		baseDn,
-		ldap.ScopeWholeSubtree,
-		ldap.NeverDerefAliases,
-		0,
-		0,
-		false,
-		filter,
-		*attributes,
-		[]ldap.Control{},
-	)
+	return c.readEntriesByFilter(ctx, false, baseDn, filter, attributes, pagingSize, scope)
+}
+
+// readEntriesByFilter reads the entries from the read or the write server.
+func (c *Client) readEntriesByFilter(
+	ctx context.Context,
+	write bool,
+	baseDn string,
+	filter string,
+	attributes *[]string,
+	pagingSize int,
+	scope int,
+) (ldapEntries *[]LdapEntry, err error) {
+	// each attempt gets a new request, SearchWithPaging adds the paging control with the cookie
+	// of its connection to the request, which the server rejects on another connection
+	newSearchRequest := func() *ldap.SearchRequest {
+		return ldap.NewSearchRequest(
+			baseDn,
+			scope,
+			ldap.NeverDerefAliases,
+			c.SearchSizeLimit,
+			c.SearchTimeLimit,
+			false,
+			filter,
+			*attributes,
+			c.controls(ctx),
+		)
+	}
 
 	var searchResult *ldap.SearchResult
 	if pagingSize == 0 {
 		// Use Search for no limit paging size
-		searchResult, err = c.Conn.Search(req)
+		err = c.do(ctx, write, true, func(conn *ldap.Conn) (err error) {
+			searchResult, err = conn.Search(newSearchRequest())
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
 
 	} else if pagingSize > 0 {
 		// Use SearchWithPaging with positive paging size
-		searchResult, err = c.Conn.SearchWithPaging(req, uint32(pagingSize))
+		err = c.do(ctx, write, true, func(conn *ldap.Conn) (err error) {
+			searchResult, err = conn.SearchWithPaging(newSearchRequest(), uint32(pagingSize))
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -101,7 +133,10 @@ func (c *Client) ReadEntriesByFilter(
 	return ldapEntries, nil
 }
 
+// ReadEntryByDN reads from the write server, so that managed entries are read
+// consistently with the changes just made to them.
 func (c *Client) ReadEntryByDN(
+	ctx context.Context,
 	dn string,
 	filter string,
 	attributes *[]string,
@@ -110,16 +145,20 @@ func (c *Client) ReadEntryByDN(
 		dn,
 		ldap.ScopeBaseObject,
 		ldap.NeverDerefAliases,
-		0,
-		0,
+		c.SearchSizeLimit,
+		c.SearchTimeLimit,
 		false,
 		filter,
 		*attributes,
-		[]ldap.Control{},
+		c.controls(ctx),
 	)
 
 	log.Printf("[INFO] attributes %v", *attributes)
-	searchResult, err := c.Conn.Search(req)
+	var searchResult *ldap.SearchResult
+	err = c.do(ctx, true, true, func(conn *ldap.Conn) (err error) {
+		searchResult, err = conn.Search(req)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -144,15 +183,23 @@ func (c *Client) ReadEntryByDN(
 }
 
 func (c *Client) CreateEntry(
+	ctx context.Context,
 	ldapEntry *LdapEntry,
 ) error {
-	addRequest := ldap.NewAddRequest(ldapEntry.Dn, []ldap.Control{})
+	addRequest := ldap.NewAddRequest(ldapEntry.Dn, c.controls(ctx))
 
 	for attrName, attrValues := range ldapEntry.Entry {
 		addRequest.Attribute(attrName, attrValues)
 	}
 
-	err := c.Conn.Add(addRequest)
+	attempts := 0
+	err := c.do(ctx, true, true, func(conn *ldap.Conn) error {
+		attempts++
+		return conn.Add(addRequest)
+	})
+	if attempts > 1 && ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+		return ldap.NewError(ldap.LDAPResultEntryAlreadyExists, fmt.Errorf("the entry '%s' already exists, it may have been added by the attempt interrupted by the connection loss: %s", ldapEntry.Dn, err))
+	}
 	if err != nil {
 		return err
 	}
@@ -160,11 +207,21 @@ func (c *Client) CreateEntry(
 	return nil
 }
 
+// UpdateEntry modifies the attributes of ldapEntry which were changed compared to ldapEntryOld.
+// With ModifyStrategyValues only the added and removed values of changed attributes having
+// more than one value are sent instead of replacing all their values.
 func (c *Client) UpdateEntry(
+	ctx context.Context,
+	ldapEntryOld *LdapEntry,
 	ldapEntry *LdapEntry,
 	deletedAttributeNameSet, addedAttributeNameSet, changedAttributeNameSet *schema.Set,
+	modifyStrategy string,
 ) error {
-	modifyRequest := ldap.NewModifyRequest(ldapEntry.Dn, []ldap.Control{})
+	controls, err := c.writeControls(ctx, ldapEntry.Dn)
+	if err != nil {
+		return err
+	}
+	modifyRequest := ldap.NewModifyRequest(ldapEntry.Dn, controls)
 
 	for _, attributeName := range deletedAttributeNameSet.List() {
 		modifyRequest.Changes = append(modifyRequest.Changes, ldap.Change{
@@ -184,30 +241,334 @@ func (c *Client) UpdateEntry(
 			},
 		})
 	}
+	// only replacing attributes can safely be repeated once the request may have been performed, unless the entry is asserted
+	retry := deletedAttributeNameSet.Len() == 0 && addedAttributeNameSet.Len() == 0 && !hasAssertion(controls)
 	for _, attributeName := range changedAttributeNameSet.List() {
-		modifyRequest.Changes = append(modifyRequest.Changes, ldap.Change{
-			Operation: ldap.ReplaceAttribute,
-			Modification: ldap.PartialAttribute{
-				Type: attributeName.(string),
-				Vals: ldapEntry.Entry[attributeName.(string)],
-			},
-		})
+		oldValues := ldapEntryOld.Entry[attributeName.(string)]
+		newValues := ldapEntry.Entry[attributeName.(string)]
+		if modifyStrategy != ModifyStrategyValues || len(oldValues) <= 1 || len(newValues) <= 1 {
+			modifyRequest.Changes = append(modifyRequest.Changes, ldap.Change{
+				Operation: ldap.ReplaceAttribute,
+				Modification: ldap.PartialAttribute{
+					Type: attributeName.(string),
+					Vals: newValues,
+				},
+			})
+			continue
+		}
+		removedValues := valuesDifference(oldValues, newValues)
+		addedValues := valuesDifference(newValues, oldValues)
+		if len(removedValues) > 0 {
+			modifyRequest.Delete(attributeName.(string), removedValues)
+			retry = false
+		}
+		if len(addedValues) > 0 {
+			modifyRequest.Add(attributeName.(string), addedValues)
+			retry = false
+		}
+	}
+	if len(modifyRequest.Changes) == 0 {
+		return nil
 	}
 
-	err := c.Conn.Modify(modifyRequest)
+	err = c.do(ctx, true, retry, func(conn *ldap.Conn) error {
+		return conn.Modify(modifyRequest)
+	})
 	if err != nil {
 		log.Printf("[ERROR] UpdateEntry - error modifying LDAP object '%q' with values %v", ldapEntry.Dn, err)
+		return assertionError(ldapEntry.Dn, err)
+	}
+
+	return nil
+}
+
+// ModifyAttributeValues adds the addedValues to and deletes the deletedValues from the attribute
+// of the entry dn, leaving its other values untouched.
+// If the server refuses that, because some of the values are already present or absent,
+// e.g. after the modification was interrupted by a connection loss, the values are modified one by one
+// and the values already present or absent are skipped.
+func (c *Client) ModifyAttributeValues(ctx context.Context, dn string, attributeName string, addedValues []string, deletedValues []string) error {
+	if len(addedValues) == 0 && len(deletedValues) == 0 {
+		return nil
+	}
+	controls, err := c.writeControls(ctx, dn)
+	if err != nil {
+		return err
+	}
+	modifyRequest := ldap.NewModifyRequest(dn, controls)
+	if len(deletedValues) > 0 {
+		modifyRequest.Delete(attributeName, deletedValues)
+	}
+	if len(addedValues) > 0 {
+		modifyRequest.Add(attributeName, addedValues)
+	}
+
+	err = c.do(ctx, true, !hasAssertion(controls), func(conn *ldap.Conn) error {
+		return conn.Modify(modifyRequest)
+	})
+	if ldap.IsErrorAnyOf(err, ldap.LDAPResultAttributeOrValueExists, ldap.LDAPResultNoSuchAttribute) {
+		log.Printf("[INFO] ModifyAttributeValues - modifying the values of '%s' of LDAP object '%q' one by one: %v", attributeName, dn, err)
+		// only the first value is modified asserting the entry, it doesn't match the assertion anymore afterwards
+		changes := make([]ldap.Change, 0, len(deletedValues)+len(addedValues))
+		for _, value := range deletedValues {
+			changes = append(changes, ldap.Change{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: attributeName, Vals: []string{value}}})
+		}
+		for _, value := range addedValues {
+			changes = append(changes, ldap.Change{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: attributeName, Vals: []string{value}}})
+		}
+		for i, change := range changes {
+			if i > 0 {
+				controls = c.controls(ctx)
+			}
+			err = c.modifyAttributeValue(ctx, dn, change, controls)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		log.Printf("[ERROR] ModifyAttributeValues - error modifying the values of '%s' of LDAP object '%q': %v", attributeName, dn, err)
+		return assertionError(dn, err)
+	}
+	return nil
+}
+
+// modifyAttributeValue adds or deletes a single value of an attribute of the entry dn,
+// a value which is already present or absent is skipped.
+func (c *Client) modifyAttributeValue(ctx context.Context, dn string, change ldap.Change, controls []ldap.Control) error {
+	modifyRequest := ldap.NewModifyRequest(dn, controls)
+	modifyRequest.Changes = append(modifyRequest.Changes, change)
+	err := c.do(ctx, true, !hasAssertion(controls), func(conn *ldap.Conn) error {
+		return conn.Modify(modifyRequest)
+	})
+	if change.Operation == ldap.AddAttribute && ldap.IsErrorWithCode(err, ldap.LDAPResultAttributeOrValueExists) {
+		return nil
+	}
+	if change.Operation == ldap.DeleteAttribute && ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchAttribute) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("[ERROR] ModifyAttributeValues - error modifying the value %v of '%s' of LDAP object '%q': %v", change.Modification.Vals, change.Modification.Type, dn, err)
+		return assertionError(dn, err)
+	}
+	return nil
+}
+
+// CompareAttributeValue reports whether the attribute of the entry dn has the value, compared
+// by the server according to the equality matching rule of the attribute.
+// It reads from the write server like ReadEntryByDN.
+func (c *Client) CompareAttributeValue(ctx context.Context, dn string, attributeName string, value string) (present bool, err error) {
+	err = c.do(ctx, true, true, func(conn *ldap.Conn) (err error) {
+		present, err = conn.Compare(dn, attributeName, value)
+		return err
+	})
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchAttribute) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return present, nil
+}
+
+// RenameEntry renames the entry dn to newDn with ModifyDN, the old RDN values are deleted.
+// The entry is moved to the parent of newDn, if that differs from the parent of dn.
+func (c *Client) RenameEntry(ctx context.Context, dn string, newDn string) error {
+	newRdn, newParentDn := SplitDN(newDn)
+	_, parentDn := SplitDN(dn)
+
+	newSuperior := ""
+	if !dnEqualFold(parentDn, newParentDn) {
+		newSuperior = newParentDn
+	}
+
+	controls, err := c.writeControls(ctx, dn)
+	if err != nil {
 		return err
 	}
+	modifyDNRequest := ldap.NewModifyDNWithControlsRequest(dn, newRdn, true, newSuperior, controls)
+	attempts := 0
+	err = c.do(ctx, true, true, func(conn *ldap.Conn) error {
+		attempts++
+		return conn.ModifyDN(modifyDNRequest)
+	})
+	if attempts > 1 && ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		// renamed by the attempt interrupted by the connection loss, if the new entry exists
+		if _, readErr := c.ReadEntryByDN(ctx, newDn, "(objectClass=*)", &[]string{"1.1"}); readErr == nil {
+			return nil
+		}
+	}
+	if err != nil {
+		return assertionError(dn, err)
+	}
+	return nil
+}
 
+// DeleteEntry deletes the entry dn, if it is not protected by ProtectedDNs.
+func (c *Client) DeleteEntry(ctx context.Context, dn string) error {
+	if err := c.CheckDeletionProtection(dn, false); err != nil {
+		return err
+	}
+	controls, err := c.writeControls(ctx, dn)
+	if err != nil {
+		return err
+	}
+	return c.deleteEntry(ctx, dn, controls)
+}
+
+// MoveEntry moves the entry dn with all entries below it to container instead of deleting it,
+// the DeletedContainer is used if container is "".
+// If the container already has an entry with the same RDN, the time of the move is appended
+// to the first RDN value of the moved entry.
+// It returns the new DN of the entry.
+func (c *Client) MoveEntry(ctx context.Context, dn string, container string) (string, error) {
+	if container == "" {
+		container = c.DeletedContainer
+	}
+	if container == "" {
+		return "", fmt.Errorf("no container is configured to move the entry '%s' to", dn)
+	}
+	if err := c.CheckDeletionProtection(dn, true); err != nil {
+		return "", err
+	}
+
+	rdn, _ := SplitDN(dn)
+	newDn := rdn + "," + container
+	err := c.RenameEntry(ctx, dn, newDn)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+		// an entry with the same RDN was moved to the container before
+		uniqueRdn, rdnErr := timestampedRDN(rdn, time.Now())
+		if rdnErr != nil {
+			return "", fmt.Errorf("moving the entry '%s' to '%s' failed: %w", dn, container, rdnErr)
+		}
+		newDn = uniqueRdn + "," + container
+		err = c.RenameEntry(ctx, dn, newDn)
+	}
+	if err != nil {
+		return "", fmt.Errorf("moving the entry '%s' to '%s' failed: %w", dn, container, err)
+	}
+	return newDn, nil
+}
+
+// timestampedRDN returns rdn with the UTC time t appended to its first value, e.g. cn=jim-20240101120000.123456789Z.
+func timestampedRDN(rdn string, t time.Time) (string, error) {
+	parsedRdn, err := ldap.ParseDN(rdn)
+	if err != nil {
+		return "", err
+	}
+	if len(parsedRdn.RDNs) != 1 || len(parsedRdn.RDNs[0].Attributes) == 0 {
+		return "", fmt.Errorf("'%s' is not an RDN", rdn)
+	}
+	parsedRdn.RDNs[0].Attributes[0].Value += "-" + t.UTC().Format("20060102150405.000000000Z")
+	return parsedRdn.RDNs[0].String(), nil
+}
+
+// CheckDeletionProtection returns an error if dn is one of the ProtectedDNs or below one of them.
+// If subtree is set, it also returns an error if one of the ProtectedDNs is below dn.
+func (c *Client) CheckDeletionProtection(dn string, subtree bool) error {
+	parsedDn, err := ldap.ParseDN(dn)
+	if err != nil {
+		return err
+	}
+	for _, protectedDn := range c.ProtectedDNs {
+		parsedProtectedDn, err := ldap.ParseDN(protectedDn)
+		if err != nil {
+			return err
+		}
+		if parsedProtectedDn.EqualFold(parsedDn) || parsedProtectedDn.AncestorOfFold(parsedDn) ||
+			(subtree && parsedDn.AncestorOfFold(parsedProtectedDn)) {
+			return fmt.Errorf("the entry '%s' is protected from deletion by the protected DN '%s'", dn, protectedDn)
+		}
+	}
 	return nil
 }
 
-func (c *Client) DeleteEntry(dn string) error {
-	deleteRequest := ldap.NewDelRequest(dn, []ldap.Control{})
-	err := c.Conn.Del(deleteRequest)
+// DeleteSubtree deletes the entry dn with all entries below it.
+// The tree delete control is used if the server supports it, otherwise the
+// entries are deleted depth-first.
+func (c *Client) DeleteSubtree(ctx context.Context, dn string) error {
+	if err := c.CheckDeletionProtection(dn, true); err != nil {
+		return err
+	}
+
+	rootDSE, err := c.ReadRootDSE(ctx, &[]string{"supportedControl"})
 	if err != nil {
 		return err
 	}
+	for _, supportedControl := range rootDSE.Entry["supportedControl"] {
+		if supportedControl == ldap.ControlTypeSubtreeDelete {
+			controls, err := c.writeControls(ctx, dn)
+			if err != nil {
+				return err
+			}
+			return c.deleteEntry(ctx, dn, append(controls, ldap.NewControlSubtreeDelete()))
+		}
+	}
+
+	return c.deleteSubtree(ctx, dn)
+}
+
+// deleteSubtree deletes the entries below dn depth-first and then dn.
+// The children are read from the write server, which may have entries not replicated yet.
+func (c *Client) deleteSubtree(ctx context.Context, dn string) error {
+	children, err := c.readEntriesByFilter(ctx, true, dn, "(objectClass=*)", &[]string{"1.1"}, 1000, ldap.ScopeSingleLevel)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return err
+	}
+	if children != nil {
+		for _, child := range *children {
+			err = c.deleteSubtree(ctx, child.Dn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return c.DeleteEntry(ctx, dn)
+}
+
+func (c *Client) deleteEntry(ctx context.Context, dn string, controls []ldap.Control) error {
+	deleteRequest := ldap.NewDelRequest(dn, controls)
+	attempts := 0
+	err := c.do(ctx, true, true, func(conn *ldap.Conn) error {
+		attempts++
+		return conn.Del(deleteRequest)
+	})
+	if attempts > 1 && ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		// deleted by the attempt interrupted by the connection loss
+		return nil
+	}
+	if err != nil {
+		return assertionError(dn, err)
+	}
 	return nil
 }
+
+// dnEqualFold compares two DNs case-insensitively, falling back to comparing
+// the strings if either of them cannot be parsed.
+func dnEqualFold(dn string, otherDn string) bool {
+	parsedDn, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.EqualFold(dn, otherDn)
+	}
+	parsedOtherDn, err := ldap.ParseDN(otherDn)
+	if err != nil {
+		return strings.EqualFold(dn, otherDn)
+	}
+	return parsedDn.EqualFold(parsedOtherDn)
+}
+
+// valuesDifference returns the values which are not in otherValues.
+func valuesDifference(values []string, otherValues []string) []string {
+	others := make(map[string]bool, len(otherValues))
+	for _, otherValue := range otherValues {
+		others[otherValue] = true
+	}
+	var difference []string
+	for _, value := range values {
+		if !others[value] {
+			difference = append(difference, value)
+		}
+	}
+	return difference
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestReadEntriesByFilterRetriesPagingOnNewConnection(t *testing.T) {
+	// the server issues the cookie "1" for the second page, which is only valid on its connection,
+	// and the first connection is lost when the second page is requested
+	server := newTestServer(t, func(connection int, op *ber.Packet, controls []ldap.Control) []testResponse {
+		var cookie string
+		if control, ok := ldap.FindControl(controls, ldap.ControlTypePaging).(*ldap.ControlPaging); ok {
+			cookie = string(control.Cookie)
+		}
+		page := ldap.NewControlPaging(1)
+		switch {
+		case cookie == "":
+			page.SetCookie([]byte("1"))
+			return []testResponse{
+				{op: testSearchResultEntry("cn=a,dc=example,dc=com", map[string][]string{"cn": {"a"}})},
+				{op: testResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess), controls: []ldap.Control{page}},
+			}
+		case connection == 1:
+			return nil
+		case cookie == "1":
+			return []testResponse{
+				{op: testSearchResultEntry("cn=b,dc=example,dc=com", map[string][]string{"cn": {"b"}})},
+				{op: testResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess), controls: []ldap.Control{page}},
+			}
+		}
+		return []testResponse{{op: testResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultUnwillingToPerform)}}
+	})
+
+	c := &Client{
+		URLs:         []string{"ldap://" + server.address},
+		BindUser:     "cn=admin,dc=example,dc=com",
+		BindPassword: "admin",
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+	}
+	ldapEntries, err := c.ReadEntriesByFilter(context.Background(), "dc=example,dc=com", "(cn=*)", &[]string{"cn"}, 1, ldap.ScopeWholeSubtree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dns []string
+	for _, ldapEntry := range *ldapEntries {
+		dns = append(dns, ldapEntry.Dn)
+	}
+	if len(dns) != 2 || dns[0] != "cn=a,dc=example,dc=com" || dns[1] != "cn=b,dc=example,dc=com" {
+		t.Errorf("expected the entries 'cn=a' and 'cn=b' once, got %v", dns)
+	}
+}
+
+func TestDeleteSubtreeReadsChildrenFromTheWriteServer(t *testing.T) {
+	// the replica has neither the subtree delete control nor the child, which is not replicated yet
+	replica := newTestServer(t, func(connection int, op *ber.Packet, controls []ldap.Control) []testResponse {
+		var responses []testResponse
+		if op.Tag == ldap.ApplicationSearchRequest && op.Children[1].Value.(int64) == ldap.ScopeBaseObject {
+			responses = append(responses, testResponse{op: testSearchResultEntry("", map[string][]string{"supportedControl": {ControlTypeAssertion}})})
+		}
+		return append(responses, testResponse{op: testResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)})
+	})
+	var mutex sync.Mutex
+	deleted := make(map[string]bool)
+	primary := newTestServer(t, func(connection int, op *ber.Packet, controls []ldap.Control) []testResponse {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch op.Tag {
+		case ldap.ApplicationSearchRequest:
+			var responses []testResponse
+			if op.Children[0].Value.(string) == "cn=parent,dc=example,dc=com" && !deleted["cn=child,cn=parent,dc=example,dc=com"] {
+				responses = append(responses, testResponse{op: testSearchResultEntry("cn=child,cn=parent,dc=example,dc=com", nil)})
+			}
+			return append(responses, testResponse{op: testResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)})
+		case ldap.ApplicationDelRequest:
+			dn := op.Data.String()
+			if dn == "cn=parent,dc=example,dc=com" && !deleted["cn=child,cn=parent,dc=example,dc=com"] {
+				return []testResponse{{op: testResult(ldap.ApplicationDelResponse, ldap.LDAPResultNotAllowedOnNonLeaf)}}
+			}
+			deleted[dn] = true
+			return []testResponse{{op: testResult(ldap.ApplicationDelResponse, ldap.LDAPResultSuccess)}}
+		}
+		return []testResponse{{op: testResult(ldap.ApplicationExtendedResponse, ldap.LDAPResultUnwillingToPerform)}}
+	})
+
+	c := &Client{
+		URLs:         []string{"ldap://" + replica.address},
+		WriteURL:     "ldap://" + primary.address,
+		BindUser:     "cn=admin,dc=example,dc=com",
+		BindPassword: "admin",
+	}
+	if err := c.DeleteSubtree(context.Background(), "cn=parent,dc=example,dc=com"); err != nil {
+		t.Fatal(err)
+	}
+	if !deleted["cn=child,cn=parent,dc=example,dc=com"] || !deleted["cn=parent,dc=example,dc=com"] {
+		t.Errorf("expected the child and the parent to be deleted, got %v", deleted)
+	}
+}
+
+func TestMoveEntryMakesTheRDNUnique(t *testing.T) {
+	// the deleted container already has an entry moved there before with the RDN cn=jim
+	var mutex sync.Mutex
+	var newRdns []string
+	server := newTestServer(t, func(connection int, op *ber.Packet, controls []ldap.Control) []testResponse {
+		mutex.Lock()
+		defer mutex.Unlock()
+		newRdn := op.Children[1].Value.(string)
+		newRdns = append(newRdns, newRdn)
+		if newRdn == "cn=jim" {
+			return []testResponse{{op: testResult(ldap.ApplicationModifyDNResponse, ldap.LDAPResultEntryAlreadyExists)}}
+		}
+		return []testResponse{{op: testResult(ldap.ApplicationModifyDNResponse, ldap.LDAPResultSuccess)}}
+	})
+
+	c := &Client{
+		URLs:             []string{"ldap://" + server.address},
+		BindUser:         "cn=admin,dc=example,dc=com",
+		BindPassword:     "admin",
+		DeletedContainer: "ou=deleted,dc=example,dc=com",
+	}
+	newDn, err := c.MoveEntry(context.Background(), "cn=jim,ou=users,dc=example,dc=com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newRdns) != 2 || newDn != newRdns[1]+",ou=deleted,dc=example,dc=com" || !strings.HasPrefix(newRdns[1], "cn=jim-") {
+		t.Errorf("expected the entry to be moved with a unique RDN after the collision, got %q after %v", newDn, newRdns)
+	}
+}
+
+func TestTimestampedRDN(t *testing.T) {
+	moved := time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("CET", 3600))
+	tests := []struct {
+		rdn      string
+		expected string
+		err      bool
+	}{
+		{rdn: "cn=jim", expected: "cn=jim-20240102020405.000000006Z"},
+		{rdn: "CN=Jim Mit", expected: "cn=Jim Mit-20240102020405.000000006Z"},
+		{rdn: `cn=Mit\, Jim`, expected: `cn=Mit\, Jim-20240102020405.000000006Z`},
+		{rdn: "cn=jim,ou=users", err: true},
+		{rdn: "jim", err: true},
+	}
+	for _, test := range tests {
+		rdn, err := timestampedRDN(test.rdn, moved)
+		if test.err {
+			if err == nil {
+				t.Errorf("timestampedRDN(%q): expected an error, got %q", test.rdn, rdn)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("timestampedRDN(%q): %s", test.rdn, err)
+			continue
+		}
+		if rdn != test.expected {
+			t.Errorf("timestampedRDN(%q): expected %q, got %q", test.rdn, test.expected, rdn)
+		}
+	}
+}
@@ -25,6 +25,19 @@ func GetRDNAttributes(ldapEntry *LdapEntry, dn string) (ignoreRDNAttributes *[]s
 	return ignoreRDNAttributes
 }
 
+// SplitDN splits dn into its RDN and the DN of its parent at the first comma which is not escaped.
+func SplitDN(dn string) (rdn string, parentDn string) {
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			return strings.TrimSpace(dn[:i]), strings.TrimSpace(dn[i+1:])
+		}
+	}
+	return strings.TrimSpace(dn), ""
+}
+
 func IgnoreAndBase64encodeAttributes(ldapEntry *LdapEntry, ignoreAndBase64Encode *IgnoreAndBase64Encode) {
 	IgnoreAttributes(ldapEntry, ignoreAndBase64Encode)
 	for attributeName, attributeValues := range ldapEntry.Entry {
@@ -50,6 +63,11 @@ func IgnoreAndBase64encodeAttributes(ldapEntry *LdapEntry, ignoreAndBase64Encode
 				}
 			}
 		}
+		if ignoreAndBase64Encode.base64encodeByFunc(attributeName) {
+			for i, value := range values {
+				values[i] = base64.StdEncoding.EncodeToString([]byte(value))
+			}
+		}
 		ldapEntry.Entry[attributeName] = values
 	}
 }
@@ -87,11 +105,46 @@ func IgnoreAndBase64decodeAttributes(ldapEntry *LdapEntry, ignoreAndBase64Encode
 				}
 			}
 		}
+		if ignoreAndBase64Encode.base64encodeByFunc(attributeName) {
+			for i, value := range values {
+				bytes, err := base64.StdEncoding.DecodeString(value)
+				if err != nil {
+					return err
+				}
+				values[i] = string(bytes)
+			}
+		}
 		ldapEntry.Entry[attributeName] = values
 	}
 	return nil
 }
 
+// base64encodeByFunc reports whether the attribute is base64 encoded by Base64encodeAttributeFunc,
+// because it is neither base64 encoded by the lists nor excluded by NoBase64encodeAttributes.
+func (ignoreAndBase64Encode *IgnoreAndBase64Encode) base64encodeByFunc(attributeName string) bool {
+	if ignoreAndBase64Encode.Base64encodeAttributeFunc == nil {
+		return false
+	}
+	if ignoreAndBase64Encode.NoBase64encodeAttributes != nil {
+		for _, noBase64encodeAttribute := range *ignoreAndBase64Encode.NoBase64encodeAttributes {
+			if strings.EqualFold(noBase64encodeAttribute, attributeName) {
+				return false
+			}
+		}
+	}
+	if ignoreAndBase64Encode.Base64encodeAttributes != nil && slices.Contains(*ignoreAndBase64Encode.Base64encodeAttributes, attributeName) {
+		return false
+	}
+	if ignoreAndBase64Encode.Base64encodeAttributePatterns != nil {
+		for _, pattern := range *ignoreAndBase64Encode.Base64encodeAttributePatterns {
+			if regexp.MustCompile(pattern).MatchString(attributeName) {
+				return false
+			}
+		}
+	}
+	return ignoreAndBase64Encode.Base64encodeAttributeFunc(attributeName)
+}
+
 func IgnoreAttributes(ldapEntry *LdapEntry, ignoreAndBase64Encode *IgnoreAndBase64Encode) {
 	for attributeName, _ := range ldapEntry.Entry {
 		if ignoreAndBase64Encode.IgnoreAttributes != nil {
@@ -0,0 +1,55 @@
+package client
+
+import "testing"
+
+func TestBase64encodeByFunc(t *testing.T) {
+	binary := func(attributeName string) bool { return attributeName != "cn" }
+	tests := []struct {
+		name                          string
+		base64encodeAttributes        []string
+		base64encodeAttributePatterns []string
+		noBase64encodeAttributes      []string
+		base64encodeAttributeFunc     func(string) bool
+		expected                      bool
+	}{
+		{name: "jpegPhoto", expected: false},
+		{name: "jpegPhoto", base64encodeAttributeFunc: binary, expected: true},
+		{name: "cn", base64encodeAttributeFunc: binary, expected: false},
+		// excluded from the detection, case-insensitively
+		{name: "jpegPhoto", noBase64encodeAttributes: []string{"JPEGphoto"}, base64encodeAttributeFunc: binary, expected: false},
+		// already base64 encoded by the list or the patterns
+		{name: "jpegPhoto", base64encodeAttributes: []string{"jpegPhoto"}, base64encodeAttributeFunc: binary, expected: false},
+		{name: "jpegPhoto", base64encodeAttributePatterns: []string{"^jpeg"}, base64encodeAttributeFunc: binary, expected: false},
+		{name: "jpegPhoto", base64encodeAttributes: []string{"userCertificate"}, base64encodeAttributePatterns: []string{"^user"}, base64encodeAttributeFunc: binary, expected: true},
+	}
+	for i, test := range tests {
+		ignoreAndBase64Encode := NewIgnoreAndBase64Encode()
+		*ignoreAndBase64Encode.Base64encodeAttributes = test.base64encodeAttributes
+		*ignoreAndBase64Encode.Base64encodeAttributePatterns = test.base64encodeAttributePatterns
+		*ignoreAndBase64Encode.NoBase64encodeAttributes = test.noBase64encodeAttributes
+		ignoreAndBase64Encode.Base64encodeAttributeFunc = test.base64encodeAttributeFunc
+		if base64encode := ignoreAndBase64Encode.base64encodeByFunc(test.name); base64encode != test.expected {
+			t.Errorf("test %d: base64encodeByFunc(%q): expected %t, got %t", i, test.name, test.expected, base64encode)
+		}
+	}
+}
+
+func TestIgnoreAndBase64encodeAttributesByFunc(t *testing.T) {
+	ignoreAndBase64Encode := NewIgnoreAndBase64Encode()
+	*ignoreAndBase64Encode.Base64encodeAttributes = []string{"userCertificate"}
+	*ignoreAndBase64Encode.NoBase64encodeAttributes = []string{"audio"}
+	ignoreAndBase64Encode.Base64encodeAttributeFunc = func(attributeName string) bool { return attributeName != "cn" }
+	ldapEntry := &LdapEntry{Entry: map[string][]string{
+		"cn":              {"jim"},
+		"jpegPhoto":       {"jpeg"},
+		"userCertificate": {"cert"},
+		"audio":           {"au"},
+	}}
+	IgnoreAndBase64encodeAttributes(ldapEntry, ignoreAndBase64Encode)
+	expected := map[string]string{"cn": "jim", "jpegPhoto": "anBlZw==", "userCertificate": "Y2VydA==", "audio": "au"}
+	for attributeName, value := range expected {
+		if values := ldapEntry.Entry[attributeName]; len(values) != 1 || values[0] != value {
+			t.Errorf("expected %s to be %q, got %v", attributeName, value, values)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// pool hands out bound connections, at most size connections are in use at the same time.
+// Connections are dialed and bound on demand and kept open for reuse when they are put back.
+type pool struct {
+	dial  func(ctx context.Context) (*ldap.Conn, error)
+	slots chan struct{}
+	mutex sync.Mutex
+	idle  []*ldap.Conn
+}
+
+func newPool(size int, dial func(ctx context.Context) (*ldap.Conn, error)) *pool {
+	if size < 1 {
+		size = 1
+	}
+	return &pool{
+		dial:  dial,
+		slots: make(chan struct{}, size),
+	}
+}
+
+// get returns an idle connection or a newly dialed one,
+// it blocks while all connections are in use until ctx is done.
+// The connection has to be returned by put.
+func (p *pool) get(ctx context.Context) (*ldap.Conn, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mutex.Lock()
+	for len(p.idle) > 0 {
+		conn := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if !conn.IsClosing() {
+			p.mutex.Unlock()
+			return conn, nil
+		}
+	}
+	p.mutex.Unlock()
+
+	conn, err := p.dial(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return conn, nil
+}
+
+// put returns conn to the pool, closed connections are dropped.
+func (p *pool) put(conn *ldap.Conn) {
+	if !conn.IsClosing() {
+		p.mutex.Lock()
+		p.idle = append(p.idle, conn)
+		p.mutex.Unlock()
+	}
+	<-p.slots
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// testPool returns a pool of size dialing connections over in-memory pipes and the number of dials.
+func testPool(t *testing.T, size int) (*pool, *int) {
+	t.Helper()
+	dials := 0
+	return newPool(size, func(ctx context.Context) (*ldap.Conn, error) {
+		dials++
+		client, server := net.Pipe()
+		t.Cleanup(func() { server.Close() })
+		conn := ldap.NewConn(client, false)
+		conn.Start()
+		t.Cleanup(func() { conn.Close() })
+		return conn, nil
+	}), &dials
+}
+
+func TestPoolBlocksWhileAllConnectionsAreInUse(t *testing.T) {
+	p, dials := testPool(t, 1)
+	conn, err := p.get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected get to block until the context is done, got %v", err)
+	}
+
+	p.put(conn)
+	reused, err := p.get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reused != conn || *dials != 1 {
+		t.Errorf("expected the idle connection to be reused, got %d dials", *dials)
+	}
+	p.put(reused)
+}
+
+func TestPoolSkipsClosedIdleConnections(t *testing.T) {
+	p, dials := testPool(t, 2)
+	conn, err := p.get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.put(conn)
+	// the idle connection is closed, e.g. after the server closed it
+	conn.Close()
+
+	other, err := p.get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == conn || other.IsClosing() || *dials != 2 {
+		t.Errorf("expected a new connection instead of the closed idle one, got %d dials", *dials)
+	}
+	p.put(other)
+}
+
+func TestPoolFreesTheSlotOfDroppedConnections(t *testing.T) {
+	p, dials := testPool(t, 1)
+	conn, err := p.get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	p.put(conn)
+	if len(p.idle) != 0 {
+		t.Errorf("expected the closed connection to be dropped, got %d idle connections", len(p.idle))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	other, err := p.get(ctx)
+	if err != nil {
+		t.Fatalf("expected the slot of the dropped connection to be free, got %v", err)
+	}
+	if *dials != 2 {
+		t.Errorf("expected a new connection, got %d dials", *dials)
+	}
+	p.put(other)
+}
+
+func TestPoolFreesTheSlotOfFailedDials(t *testing.T) {
+	dialErr := errors.New("dial failed")
+	p := newPool(1, func(ctx context.Context) (*ldap.Conn, error) {
+		return nil, dialErr
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		if _, err := p.get(ctx); !errors.Is(err, dialErr) {
+			t.Fatalf("expected the dial error, got %v", err)
+		}
+	}
+}
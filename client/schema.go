@@ -0,0 +1,484 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"golang.org/x/exp/slices"
+)
+
+// syntax OIDs (RFC 4517) used to find the matching rule of attribute types without EQUALITY, e.g. in Active Directory
+const (
+	syntaxDN              = "1.3.6.1.4.1.1466.115.121.1.12"
+	syntaxDirectoryString = "1.3.6.1.4.1.1466.115.121.1.15"
+	syntaxIA5String       = "1.3.6.1.4.1.1466.115.121.1.26"
+	syntaxInteger         = "1.3.6.1.4.1.1466.115.121.1.27"
+	syntaxNumericString   = "1.3.6.1.4.1.1466.115.121.1.36"
+	syntaxTelephoneNumber = "1.3.6.1.4.1.1466.115.121.1.50"
+	syntaxBoolean         = "1.3.6.1.4.1.1466.115.121.1.7"
+	syntaxGeneralizedTime = "1.3.6.1.4.1.1466.115.121.1.24"
+	syntaxOID             = "1.3.6.1.4.1.1466.115.121.1.38"
+)
+
+// binarySyntaxes lists the syntaxes (RFC 4517, RFC 4523) whose values are always binary:
+// Certificate, Certificate List, Certificate Pair, Supported Algorithm, JPEG, Fax, Audio and Binary.
+// Octet String is not included, as its values are text for many attributes, e.g. userPassword or sshPublicKey.
+var binarySyntaxes = []string{
+	"1.3.6.1.4.1.1466.115.121.1.8",
+	"1.3.6.1.4.1.1466.115.121.1.9",
+	"1.3.6.1.4.1.1466.115.121.1.10",
+	"1.3.6.1.4.1.1466.115.121.1.49",
+	"1.3.6.1.4.1.1466.115.121.1.28",
+	"1.3.6.1.4.1.1466.115.121.1.23",
+	"1.3.6.1.4.1.1466.115.121.1.4",
+	"1.3.6.1.4.1.1466.115.121.1.5",
+}
+
+var syntaxMatchingRules = map[string]string{
+	syntaxDN:              "distinguishedNameMatch",
+	syntaxDirectoryString: "caseIgnoreMatch",
+	syntaxIA5String:       "caseIgnoreIA5Match",
+	syntaxInteger:         "integerMatch",
+	syntaxNumericString:   "numericStringMatch",
+	syntaxTelephoneNumber: "telephoneNumberMatch",
+	syntaxBoolean:         "booleanMatch",
+	syntaxGeneralizedTime: "generalizedTimeMatch",
+	syntaxOID:             "objectIdentifierMatch",
+}
+
+// AttributeType is an attribute type description (RFC 4512) of the subschema.
+type AttributeType struct {
+	OID                string
+	Names              []string
+	Sup                string
+	Equality           string
+	Syntax             string
+	SingleValue        bool
+	NoUserModification bool
+}
+
+const (
+	ObjectClassAbstract   = "ABSTRACT"
+	ObjectClassStructural = "STRUCTURAL"
+	ObjectClassAuxiliary  = "AUXILIARY"
+)
+
+// ObjectClass is an object class description (RFC 4512) of the subschema.
+type ObjectClass struct {
+	OID   string
+	Names []string
+	Sup   []string
+	Kind  string
+	Must  []string
+	May   []string
+}
+
+// DITContentRule is a DIT content rule description (RFC 4512) of the subschema,
+// it applies to the structural object class with its OID.
+type DITContentRule struct {
+	OID   string
+	Names []string
+	Aux   []string
+	Must  []string
+	May   []string
+	Not   []string
+}
+
+// Schema holds the attribute types, object classes and DIT content rules of the subschema of the server.
+type Schema struct {
+	attributeTypes  map[string]*AttributeType
+	objectClasses   map[string]*ObjectClass
+	dITContentRules map[string]*DITContentRule
+}
+
+// Schema returns the subschema of the server, it is read only once.
+// Errors are only kept if the server answered that the subschema cannot be read,
+// after transient errors, e.g. a lost connection, the subschema is read again by the next call.
+func (c *Client) Schema(ctx context.Context) (*Schema, error) {
+	c.schemaMutex.Lock()
+	defer c.schemaMutex.Unlock()
+
+	if c.schema != nil || c.schemaErr != nil {
+		return c.schema, c.schemaErr
+	}
+	schema, err := c.readSchema(ctx)
+	if err == nil || !IsTransientError(err) {
+		c.schema, c.schemaErr = schema, err
+	}
+	return schema, err
+}
+
+// IsTransientError reports whether the operation may succeed if it is repeated later.
+func IsTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return isConnectionError(err) || ldap.IsErrorAnyOf(err,
+		ldap.LDAPResultBusy,
+		ldap.LDAPResultUnavailable,
+		ldap.LDAPResultTimeLimitExceeded,
+		ldap.LDAPResultAdminLimitExceeded,
+	)
+}
+
+func (c *Client) readSchema(ctx context.Context) (*Schema, error) {
+	subschemaSubentry := "cn=Subschema"
+	rootDSE, err := c.ReadRootDSE(ctx, &[]string{"subschemaSubentry"})
+	if err != nil {
+		return nil, err
+	}
+	if values := rootDSE.Entry["subschemaSubentry"]; len(values) > 0 {
+		subschemaSubentry = values[0]
+	}
+
+	subschema, err := c.ReadEntryByDN(ctx, subschemaSubentry, "(objectClass=*)", &[]string{"attributeTypes", "objectClasses", "dITContentRules"})
+	if err != nil {
+		return nil, fmt.Errorf("reading the subschema '%s' failed: %w", subschemaSubentry, err)
+	}
+	return newSchema(subschema.Entry)
+}
+
+// newSchema parses the attributeTypes, objectClasses and dITContentRules of the subschema.
+func newSchema(subschema map[string][]string) (*Schema, error) {
+	schema := &Schema{
+		attributeTypes:  make(map[string]*AttributeType),
+		objectClasses:   make(map[string]*ObjectClass),
+		dITContentRules: make(map[string]*DITContentRule),
+	}
+	for attributeName, values := range subschema {
+		for _, value := range values {
+			oid, fields, err := parseSchemaDescription(value)
+			if err != nil {
+				return nil, err
+			}
+			switch strings.ToLower(attributeName) {
+			case "attributetypes":
+				attributeType := &AttributeType{
+					OID:                oid,
+					Names:              fields["NAME"],
+					Sup:                firstValue(fields["SUP"]),
+					Equality:           firstValue(fields["EQUALITY"]),
+					Syntax:             firstValue(fields["SYNTAX"]),
+					SingleValue:        fields["SINGLE-VALUE"] != nil,
+					NoUserModification: fields["NO-USER-MODIFICATION"] != nil,
+				}
+				// the syntax may have a length bound, e.g. 1.3.6.1.4.1.1466.115.121.1.15{32768}
+				if i := strings.Index(attributeType.Syntax, "{"); i >= 0 {
+					attributeType.Syntax = attributeType.Syntax[:i]
+				}
+				addSchemaElement(schema.attributeTypes, oid, attributeType.Names, attributeType)
+			case "objectclasses":
+				objectClass := &ObjectClass{
+					OID:   oid,
+					Names: fields["NAME"],
+					Sup:   fields["SUP"],
+					Kind:  ObjectClassStructural,
+					Must:  fields["MUST"],
+					May:   fields["MAY"],
+				}
+				if fields[ObjectClassAbstract] != nil {
+					objectClass.Kind = ObjectClassAbstract
+				} else if fields[ObjectClassAuxiliary] != nil {
+					objectClass.Kind = ObjectClassAuxiliary
+				}
+				addSchemaElement(schema.objectClasses, oid, objectClass.Names, objectClass)
+			case "ditcontentrules":
+				dITContentRule := &DITContentRule{
+					OID:   oid,
+					Names: fields["NAME"],
+					Aux:   fields["AUX"],
+					Must:  fields["MUST"],
+					May:   fields["MAY"],
+					Not:   fields["NOT"],
+				}
+				// the rule applies to the structural object class with the same OID
+				schema.dITContentRules[strings.ToLower(oid)] = dITContentRule
+			}
+		}
+	}
+	return schema, nil
+}
+
+func addSchemaElement[T any](elements map[string]*T, oid string, names []string, element *T) {
+	elements[strings.ToLower(oid)] = element
+	for _, name := range names {
+		elements[strings.ToLower(name)] = element
+	}
+}
+
+// AttributeType returns the attribute type with the name or OID, nil if it is unknown.
+// Options like ";binary" are ignored.
+func (s *Schema) AttributeType(name string) *AttributeType {
+	if i := strings.Index(name, ";"); i >= 0 {
+		name = name[:i]
+	}
+	return s.attributeTypes[strings.ToLower(name)]
+}
+
+// CanonicalAttributeName returns the first name of the attribute type of the attribute description name
+// followed by its options, e.g. `cn` for `commonName` or `CN`, the name itself if the attribute type is unknown.
+func (s *Schema) CanonicalAttributeName(name string) string {
+	attributeType := s.AttributeType(name)
+	if attributeType == nil || len(attributeType.Names) == 0 {
+		return name
+	}
+	options := ""
+	if i := strings.Index(name, ";"); i >= 0 {
+		options = strings.ToLower(name[i:])
+	}
+	return attributeType.Names[0] + options
+}
+
+// SameAttribute reports whether the attribute descriptions name and otherName denote the same attribute,
+// i.e. the same attribute type, e.g. by an alias, with the same options, ignoring case.
+func (s *Schema) SameAttribute(name string, otherName string) bool {
+	return strings.EqualFold(s.CanonicalAttributeName(name), s.CanonicalAttributeName(otherName))
+}
+
+// EqualityMatchingRule returns the equality matching rule of the attribute, inherited from its
+// super types or derived from its syntax, "" if it is unknown.
+func (s *Schema) EqualityMatchingRule(name string) string {
+	// the depth is limited to protect against cycles
+	for depth := 0; depth < 16; depth++ {
+		attributeType := s.AttributeType(name)
+		if attributeType == nil {
+			return ""
+		}
+		if attributeType.Equality != "" {
+			return attributeType.Equality
+		}
+		if attributeType.Sup == "" {
+			return syntaxMatchingRules[attributeType.Syntax]
+		}
+		name = attributeType.Sup
+	}
+	return ""
+}
+
+// HasBinaryOption reports whether the attribute description has the option ";binary" (RFC 4522).
+func HasBinaryOption(name string) bool {
+	for _, option := range strings.Split(name, ";")[1:] {
+		if strings.EqualFold(option, "binary") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBinary reports whether the values of the attribute are binary according to its syntax,
+// inherited from its super types, or because it has the option ";binary".
+func (s *Schema) IsBinary(name string) bool {
+	if HasBinaryOption(name) {
+		return true
+	}
+	// the depth is limited to protect against cycles
+	for depth := 0; depth < 16; depth++ {
+		attributeType := s.AttributeType(name)
+		if attributeType == nil {
+			return false
+		}
+		if attributeType.Syntax != "" || attributeType.Sup == "" {
+			return slices.Contains(binarySyntaxes, attributeType.Syntax)
+		}
+		name = attributeType.Sup
+	}
+	return false
+}
+
+// NormalizeValue returns the value of the attribute normalized according to its equality matching rule,
+// so that values matching each other have the same normalized value.
+func (s *Schema) NormalizeValue(name string, value string) string {
+	switch s.EqualityMatchingRule(name) {
+	case "caseIgnoreMatch", "caseIgnoreIA5Match", "caseIgnoreListMatch", "objectIdentifierMatch":
+		return strings.ToLower(strings.Join(strings.Fields(value), " "))
+	case "caseExactMatch", "caseExactIA5Match":
+		return strings.Join(strings.Fields(value), " ")
+	case "distinguishedNameMatch":
+		return normalizeDN(value)
+	case "uniqueMemberMatch":
+		// the optional unique identifier is appended to the DN after '#'
+		if i := strings.LastIndex(value, "#'"); i >= 0 {
+			return normalizeDN(value[:i]) + value[i:]
+		}
+		return normalizeDN(value)
+	case "telephoneNumberMatch":
+		return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(value))
+	case "numericStringMatch":
+		return strings.ReplaceAll(value, " ", "")
+	case "integerMatch":
+		if integer, ok := new(big.Int).SetString(strings.TrimSpace(value), 10); ok {
+			return integer.String()
+		}
+	case "booleanMatch":
+		return strings.ToUpper(value)
+	case "generalizedTimeMatch":
+		for _, layout := range []string{"20060102150405.999999999Z0700", "200601021504Z0700", "2006010215Z0700"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t.UTC().Format("20060102150405.999999999Z")
+			}
+		}
+	}
+	return value
+}
+
+// ValuesEqual reports whether values and otherValues of the attribute match each other
+// according to its equality matching rule, ignoring their order.
+func (s *Schema) ValuesEqual(name string, values []string, otherValues []string) bool {
+	if len(values) != len(otherValues) {
+		return false
+	}
+	normalizedValues := s.normalizeValues(name, values)
+	normalizedOtherValues := s.normalizeValues(name, otherValues)
+	for i := range normalizedValues {
+		if normalizedValues[i] != normalizedOtherValues[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// EntriesEqual reports whether the attributes of entry and otherEntry match each other,
+// the names of the attributes are compared by their attribute types.
+func (s *Schema) EntriesEqual(entry map[string][]string, otherEntry map[string][]string) bool {
+	canonicalEntry := s.CanonicalizeAttributeNames(entry)
+	canonicalOtherEntry := s.CanonicalizeAttributeNames(otherEntry)
+	if len(canonicalEntry) != len(canonicalOtherEntry) {
+		return false
+	}
+	for name, values := range canonicalEntry {
+		otherValues, ok := canonicalOtherEntry[name]
+		if !ok || !s.ValuesEqual(name, values, otherValues) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanonicalizeAttributeNames returns the entry with the canonical names of its attributes in lower case,
+// the values of attributes with the same canonical name are merged.
+func (s *Schema) CanonicalizeAttributeNames(entry map[string][]string) map[string][]string {
+	canonicalEntry := make(map[string][]string, len(entry))
+	for name, values := range entry {
+		canonicalName := strings.ToLower(s.CanonicalAttributeName(name))
+		canonicalEntry[canonicalName] = append(canonicalEntry[canonicalName], values...)
+	}
+	return canonicalEntry
+}
+
+func (s *Schema) normalizeValues(name string, values []string) []string {
+	normalizedValues := make([]string, len(values))
+	for i, value := range values {
+		normalizedValues[i] = s.NormalizeValue(name, value)
+	}
+	sort.Strings(normalizedValues)
+	return normalizedValues
+}
+
+// normalizeDN returns dn with lower case attribute types and values and without insignificant spaces.
+func normalizeDN(dn string) string {
+	parsedDn, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+	rdns := make([]string, len(parsedDn.RDNs))
+	for i, rdn := range parsedDn.RDNs {
+		attributes := make([]string, len(rdn.Attributes))
+		for j, attribute := range rdn.Attributes {
+			attributes[j] = strings.ToLower(attribute.Type) + "=" + strings.ToLower(strings.Join(strings.Fields(attribute.Value), " "))
+		}
+		sort.Strings(attributes)
+		rdns[i] = strings.Join(attributes, "+")
+	}
+	return strings.Join(rdns, ",")
+}
+
+// parseSchemaDescription parses a schema description (RFC 4512, section 4.1) into its OID and
+// its fields, e.g. `( 2.5.4.3 NAME ( 'cn' 'commonName' ) SUP name )` into 2.5.4.3 and
+// NAME: [cn commonName], SUP: [name]. Fields without value, like SINGLE-VALUE, have an empty list.
+func parseSchemaDescription(description string) (oid string, fields map[string][]string, err error) {
+	tokens, err := tokenizeSchemaDescription(description)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(tokens) < 3 || tokens[0] != "(" || tokens[len(tokens)-1] != ")" {
+		return "", nil, fmt.Errorf("invalid schema description: %s", description)
+	}
+	oid = tokens[1]
+	fields = make(map[string][]string)
+	for i := 2; i < len(tokens)-1; i++ {
+		keyword := strings.ToUpper(tokens[i])
+		values := []string{}
+		if i+1 < len(tokens)-1 {
+			switch next := tokens[i+1]; {
+			case next == "(":
+				for i += 2; i < len(tokens)-1 && tokens[i] != ")"; i++ {
+					if tokens[i] != "$" {
+						values = append(values, strings.Trim(tokens[i], "'"))
+					}
+				}
+			case !isSchemaKeyword(next):
+				values = append(values, strings.Trim(next, "'"))
+				i++
+			}
+		}
+		if fields[keyword] == nil {
+			fields[keyword] = []string{}
+		}
+		fields[keyword] = append(fields[keyword], values...)
+	}
+	return oid, fields, nil
+}
+
+func tokenizeSchemaDescription(description string) (tokens []string, err error) {
+	for i := 0; i < len(description); {
+		switch c := description[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == '$':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '\'':
+			end := strings.IndexByte(description[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in schema description: %s", description)
+			}
+			tokens = append(tokens, description[i:i+end+2])
+			i += end + 2
+		default:
+			end := strings.IndexAny(description[i:], " \t\n()$'")
+			if end < 0 {
+				end = len(description) - i
+			}
+			tokens = append(tokens, description[i:i+end])
+			i += end
+		}
+	}
+	return tokens, nil
+}
+
+// isSchemaKeyword reports whether token is a keyword, which are in upper case with hyphens,
+// while oids, names and quoted strings are values.
+func isSchemaKeyword(token string) bool {
+	if token == "" || token[0] == '\'' {
+		return false
+	}
+	for _, c := range token {
+		if !(c >= 'A' && c <= 'Z' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
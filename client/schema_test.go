@@ -0,0 +1,230 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testSubschema is a part of the subschema of OpenLDAP.
+var testSubschema = map[string][]string{
+	"attributeTypes": {
+		"( 2.5.4.0 NAME 'objectClass' EQUALITY objectIdentifierMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.38 )",
+		"( 2.5.4.41 NAME 'name' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15{32768} )",
+		"( 2.5.4.3 NAME ( 'cn' 'commonName' ) DESC 'RFC4519: common name(s) for which the entity is known by' SUP name )",
+		"( 2.5.4.4 NAME ( 'sn' 'surname' ) SUP name )",
+		"( 2.5.4.13 NAME 'description' EQUALITY caseIgnoreMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15{1024} )",
+		"( 2.5.4.20 NAME 'telephoneNumber' EQUALITY telephoneNumberMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.50{32} )",
+		"( 2.5.4.31 NAME 'member' SUP distinguishedName )",
+		"( 2.5.4.49 NAME 'distinguishedName' EQUALITY distinguishedNameMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.12 )",
+		"( 2.5.4.50 NAME 'uniqueMember' EQUALITY uniqueMemberMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.34 )",
+		"( 0.9.2342.19200300.100.1.1 NAME ( 'uid' 'userid' ) EQUALITY caseIgnoreMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15{256} )",
+		"( 0.9.2342.19200300.100.1.3 NAME ( 'mail' 'rfc822Mailbox' ) EQUALITY caseIgnoreIA5Match SYNTAX 1.3.6.1.4.1.1466.115.121.1.26{256} )",
+		"( 1.3.6.1.1.1.1.0 NAME 'uidNumber' EQUALITY integerMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 SINGLE-VALUE )",
+		"( 2.5.4.35 NAME 'userPassword' EQUALITY octetStringMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.40{128} )",
+		"( 0.9.2342.19200300.100.1.60 NAME 'jpegPhoto' SYNTAX 1.3.6.1.4.1.1466.115.121.1.28 )",
+		"( 2.5.4.36 NAME 'userCertificate' SYNTAX 1.3.6.1.4.1.1466.115.121.1.8 )",
+		"( 1.2.840.113556.1.4.2 NAME 'objectGUID' SYNTAX 1.3.6.1.4.1.1466.115.121.1.40 SINGLE-VALUE )",
+		"( 1.3.6.1.1.16.4 NAME 'entryUUID' EQUALITY UUIDMatch SYNTAX 1.3.6.1.1.16.1 SINGLE-VALUE NO-USER-MODIFICATION USAGE directoryOperation )",
+		// Active Directory doesn't specify EQUALITY
+		"( 1.2.840.113556.1.4.221 NAME 'sAMAccountName' SYNTAX '1.3.6.1.4.1.1466.115.121.1.15' SINGLE-VALUE )",
+	},
+	"objectClasses": {
+		"( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )",
+		"( 2.5.6.6 NAME 'person' SUP top STRUCTURAL MUST ( sn $ cn ) MAY ( userPassword $ telephoneNumber $ description ) )",
+		"( 2.16.840.1.113730.3.2.2 NAME 'inetOrgPerson' SUP person STRUCTURAL MAY ( uid $ mail $ jpegPhoto ) )",
+		"( 2.5.6.9 NAME 'groupOfNames' SUP top STRUCTURAL MUST ( member $ cn ) MAY description )",
+		"( 2.5.6.17 NAME 'groupOfUniqueNames' SUP top STRUCTURAL MUST ( uniqueMember $ cn ) MAY description )",
+		"( 1.3.6.1.1.1.2.0 NAME 'posixAccount' SUP top AUXILIARY MUST ( cn $ uid $ uidNumber ) )",
+		"( 1.3.6.1.4.1.1466.101.120.111 NAME 'extensibleObject' SUP top AUXILIARY )",
+	},
+	"dITContentRules": {
+		"( 2.5.6.9 NAME 'groupOfNamesRule' NOT description )",
+	},
+}
+
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+	schema, err := newSchema(testSubschema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestParseSchemaDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		oid         string
+		fields      map[string][]string
+		err         bool
+	}{
+		{
+			description: "( 2.5.4.3 NAME ( 'cn' 'commonName' ) DESC 'RFC4519: common name(s)' SUP name )",
+			oid:         "2.5.4.3",
+			fields: map[string][]string{
+				"NAME": {"cn", "commonName"},
+				"DESC": {"RFC4519: common name(s)"},
+				"SUP":  {"name"},
+			},
+		},
+		{
+			description: "( 1.3.6.1.1.1.1.0 NAME 'uidNumber' EQUALITY integerMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 SINGLE-VALUE )",
+			oid:         "1.3.6.1.1.1.1.0",
+			fields: map[string][]string{
+				"NAME":         {"uidNumber"},
+				"EQUALITY":     {"integerMatch"},
+				"SYNTAX":       {"1.3.6.1.4.1.1466.115.121.1.27"},
+				"SINGLE-VALUE": {},
+			},
+		},
+		{
+			description: "( 2.5.6.6 NAME 'person' SUP top STRUCTURAL MUST ( sn $ cn ) MAY ( userPassword $ telephoneNumber ) )",
+			oid:         "2.5.6.6",
+			fields: map[string][]string{
+				"NAME":       {"person"},
+				"SUP":        {"top"},
+				"STRUCTURAL": {},
+				"MUST":       {"sn", "cn"},
+				"MAY":        {"userPassword", "telephoneNumber"},
+			},
+		},
+		{
+			description: "( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass X-ORIGIN 'RFC 4512' )",
+			oid:         "2.5.6.0",
+			fields: map[string][]string{
+				"NAME":     {"top"},
+				"ABSTRACT": {},
+				"MUST":     {"objectClass"},
+				"X-ORIGIN": {"RFC 4512"},
+			},
+		},
+		{
+			description: "( 2.5.4.3 NAME 'cn' DESC 'unterminated )",
+			err:         true,
+		},
+		{
+			description: "2.5.4.3 NAME 'cn'",
+			err:         true,
+		},
+	}
+	for _, test := range tests {
+		oid, fields, err := parseSchemaDescription(test.description)
+		if test.err {
+			if err == nil {
+				t.Errorf("parseSchemaDescription(%q): expected an error", test.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSchemaDescription(%q): %s", test.description, err)
+			continue
+		}
+		if oid != test.oid {
+			t.Errorf("parseSchemaDescription(%q): expected OID %q, got %q", test.description, test.oid, oid)
+		}
+		if !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("parseSchemaDescription(%q): expected %v, got %v", test.description, test.fields, fields)
+		}
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	schema := testSchema(t)
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"cn", "  Jim   Mit ", "jim mit"},
+		{"commonName", "Jim Mit", "jim mit"},
+		{"mail", "Jim.Mit@Example.COM", "jim.mit@example.com"},
+		{"member", "CN=Jim Mit, OU=Users,DC=example,DC=com", "cn=jim mit,ou=users,dc=example,dc=com"},
+		{"uniqueMember", "CN=Jim,DC=example#'0101'B", "cn=jim,dc=example#'0101'B"},
+		{"telephoneNumber", "+49 30-1234", "+49301234"},
+		{"uidNumber", " 0042", "42"},
+		{"objectClass", "InetOrgPerson", "inetorgperson"},
+		{"sAMAccountName", "JimMit", "jimmit"},
+		{"userPassword", "Secret", "Secret"},
+		{"unknown", "Value", "Value"},
+	}
+	for _, test := range tests {
+		if normalized := schema.NormalizeValue(test.name, test.value); normalized != test.expected {
+			t.Errorf("NormalizeValue(%q, %q): expected %q, got %q", test.name, test.value, test.expected, normalized)
+		}
+	}
+}
+
+func TestValuesEqual(t *testing.T) {
+	schema := testSchema(t)
+	tests := []struct {
+		name        string
+		values      []string
+		otherValues []string
+		expected    bool
+	}{
+		{"cn", []string{"Jim Mit"}, []string{"jim mit"}, true},
+		{"member", []string{"cn=a,dc=example", "cn=b,dc=example"}, []string{"CN=B, DC=example", "CN=A,DC=Example"}, true},
+		{"member", []string{"cn=a,dc=example"}, []string{"cn=a,dc=example", "cn=b,dc=example"}, false},
+		{"uidNumber", []string{"42"}, []string{"042"}, true},
+		{"userPassword", []string{"Secret"}, []string{"secret"}, false},
+		{"mail", []string{"a@example.com"}, []string{"b@example.com"}, false},
+	}
+	for _, test := range tests {
+		if equal := schema.ValuesEqual(test.name, test.values, test.otherValues); equal != test.expected {
+			t.Errorf("ValuesEqual(%q, %v, %v): expected %t, got %t", test.name, test.values, test.otherValues, test.expected, equal)
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	schema := testSchema(t)
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"jpegPhoto", true},
+		{"JPEGPHOTO", true},
+		{"userCertificate", true},
+		{"userCertificate;binary", true},
+		{"cn;binary", true},
+		// Octet String values are often text
+		{"userPassword", false},
+		{"objectGUID", false},
+		{"cn", false},
+		{"unknown", false},
+	}
+	for _, test := range tests {
+		if binary := schema.IsBinary(test.name); binary != test.expected {
+			t.Errorf("IsBinary(%q): expected %t, got %t", test.name, test.expected, binary)
+		}
+	}
+}
+
+func TestEntriesEqual(t *testing.T) {
+	schema := testSchema(t)
+	entry := map[string][]string{
+		"objectClass": {"inetOrgPerson"},
+		"cn":          {"Jim Mit"},
+		"SN":          {"Mit"},
+	}
+	otherEntry := map[string][]string{
+		"objectclass": {"inetorgperson"},
+		"CN":          {"jim mit"},
+		"sn":          {"MIT"},
+	}
+	if !schema.EntriesEqual(entry, otherEntry) {
+		t.Errorf("expected %v and %v to be equal", entry, otherEntry)
+	}
+	aliasEntry := map[string][]string{
+		"objectClass": {"inetOrgPerson"},
+		"commonName":  {"jim mit"},
+		"surname":     {"Mit"},
+	}
+	if !schema.EntriesEqual(entry, aliasEntry) {
+		t.Errorf("expected %v and %v to be equal", entry, aliasEntry)
+	}
+	otherEntry["mail"] = []string{"jim.mit@example.com"}
+	if schema.EntriesEqual(entry, otherEntry) {
+		t.Errorf("expected %v and %v to differ", entry, otherEntry)
+	}
+}
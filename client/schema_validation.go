@@ -0,0 +1,172 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// objectClassExtensibleObject permits all user attributes (RFC 4512, section 4.3).
+const objectClassExtensibleObject = "extensibleObject"
+
+// ValidateEntry checks the attributes of ldapEntry against the object classes, attribute types and
+// DIT content rules of the schema: the object classes have to be known and include exactly one chain
+// of structural object classes, the attributes have to be known and permitted, the required attributes
+// have to be present and single-valued attributes must not have more than one value.
+// The attributes of the RDN of ldapEntry.Dn and the attributes for which assumePresent returns true are
+// assumed to be present.
+func (s *Schema) ValidateEntry(ldapEntry *LdapEntry, assumePresent func(attributeName string) bool) (errs []error) {
+	// the errors are sorted, as the attributes are checked in random order
+	defer func() {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	}()
+
+	present := make(map[*AttributeType]bool)
+	var objectClassNames []string
+	undefined := false
+	for attributeName, values := range ldapEntry.Entry {
+		attributeType := s.AttributeType(attributeName)
+		if attributeType == nil {
+			errs = append(errs, fmt.Errorf("undefined attribute type '%s'", attributeName))
+			undefined = true
+			continue
+		}
+		present[attributeType] = true
+		if attributeType.SingleValue && len(values) > 1 {
+			errs = append(errs, fmt.Errorf("attribute '%s' is single-valued, but has %d values", attributeName, len(values)))
+		}
+		if attributeType == s.AttributeType("objectClass") {
+			objectClassNames = append(objectClassNames, values...)
+		}
+	}
+	if rdn, _ := SplitDN(ldapEntry.Dn); rdn != "" {
+		for _, attributeValue := range strings.Split(rdn, "+") {
+			if attributeName, _, ok := strings.Cut(attributeValue, "="); ok {
+				if attributeType := s.AttributeType(strings.TrimSpace(attributeName)); attributeType != nil {
+					present[attributeType] = true
+				}
+			}
+		}
+	}
+	if len(objectClassNames) == 0 {
+		return append(errs, fmt.Errorf("attribute 'objectClass' is required"))
+	}
+
+	objectClasses := make(map[*ObjectClass]bool)
+	var structuralObjectClasses []*ObjectClass
+	var auxiliaryObjectClasses []*ObjectClass
+	for _, objectClassName := range objectClassNames {
+		objectClass := s.objectClasses[strings.ToLower(objectClassName)]
+		if objectClass == nil {
+			errs = append(errs, fmt.Errorf("undefined object class '%s'", objectClassName))
+			undefined = true
+			continue
+		}
+		switch objectClass.Kind {
+		case ObjectClassStructural:
+			structuralObjectClasses = append(structuralObjectClasses, objectClass)
+		case ObjectClassAuxiliary:
+			auxiliaryObjectClasses = append(auxiliaryObjectClasses, objectClass)
+		}
+		s.addSuperiorObjectClasses(objectClasses, objectClass)
+	}
+	if undefined {
+		return errs
+	}
+
+	// the structural object classes have to be superiors of the most specific one
+	var structuralObjectClass *ObjectClass
+	for _, objectClass := range structuralObjectClasses {
+		superiors := make(map[*ObjectClass]bool)
+		s.addSuperiorObjectClasses(superiors, objectClass)
+		chain := true
+		for _, other := range structuralObjectClasses {
+			chain = chain && superiors[other]
+		}
+		if chain {
+			structuralObjectClass = objectClass
+		}
+	}
+	if structuralObjectClass == nil {
+		if len(structuralObjectClasses) == 0 {
+			return append(errs, fmt.Errorf("no structural object class in '%s'", strings.Join(objectClassNames, "', '")))
+		}
+		return append(errs, fmt.Errorf("the structural object classes '%s' do not form one chain", strings.Join(objectClassNamesOf(structuralObjectClasses), "', '")))
+	}
+
+	must := make(map[*AttributeType]string)
+	may := make(map[*AttributeType]string)
+	extensible := false
+	for objectClass := range objectClasses {
+		s.addAttributeTypes(must, objectClass.Must)
+		s.addAttributeTypes(may, objectClass.May)
+		extensible = extensible || objectClass == s.objectClasses[strings.ToLower(objectClassExtensibleObject)]
+	}
+	forbidden := make(map[*AttributeType]string)
+	if rule := s.dITContentRules[strings.ToLower(structuralObjectClass.OID)]; rule != nil {
+		aux := make(map[*ObjectClass]bool)
+		for _, auxName := range rule.Aux {
+			if objectClass := s.objectClasses[strings.ToLower(auxName)]; objectClass != nil {
+				aux[objectClass] = true
+			}
+		}
+		for _, objectClass := range auxiliaryObjectClasses {
+			if !aux[objectClass] {
+				errs = append(errs, fmt.Errorf("auxiliary object class '%s' is not allowed by the DIT content rule of '%s'", objectClassNamesOf([]*ObjectClass{objectClass})[0], objectClassNamesOf([]*ObjectClass{structuralObjectClass})[0]))
+			}
+		}
+		s.addAttributeTypes(must, rule.Must)
+		s.addAttributeTypes(may, rule.May)
+		s.addAttributeTypes(forbidden, rule.Not)
+	}
+
+	for attributeType, attributeName := range must {
+		if !present[attributeType] && !attributeType.NoUserModification && (assumePresent == nil || !assumePresent(attributeName)) {
+			errs = append(errs, fmt.Errorf("attribute '%s' is required by the object classes", attributeName))
+		}
+	}
+	for attributeName := range ldapEntry.Entry {
+		attributeType := s.AttributeType(attributeName)
+		if _, precluded := forbidden[attributeType]; precluded {
+			errs = append(errs, fmt.Errorf("attribute '%s' is precluded by the DIT content rule of '%s'", attributeName, objectClassNamesOf([]*ObjectClass{structuralObjectClass})[0]))
+			continue
+		}
+		_, required := must[attributeType]
+		_, allowed := may[attributeType]
+		if !required && !allowed && !extensible && !attributeType.NoUserModification {
+			errs = append(errs, fmt.Errorf("attribute '%s' is not allowed by the object classes", attributeName))
+		}
+	}
+	return errs
+}
+
+// addSuperiorObjectClasses adds objectClass and its superior object classes to objectClasses.
+func (s *Schema) addSuperiorObjectClasses(objectClasses map[*ObjectClass]bool, objectClass *ObjectClass) {
+	if objectClass == nil || objectClasses[objectClass] {
+		return
+	}
+	objectClasses[objectClass] = true
+	for _, sup := range objectClass.Sup {
+		s.addSuperiorObjectClasses(objectClasses, s.objectClasses[strings.ToLower(sup)])
+	}
+}
+
+// addAttributeTypes adds the known attribute types of names with their names to attributeTypes.
+func (s *Schema) addAttributeTypes(attributeTypes map[*AttributeType]string, names []string) {
+	for _, name := range names {
+		if attributeType := s.AttributeType(name); attributeType != nil {
+			attributeTypes[attributeType] = name
+		}
+	}
+}
+
+func objectClassNamesOf(objectClasses []*ObjectClass) []string {
+	names := make([]string, len(objectClasses))
+	for i, objectClass := range objectClasses {
+		names[i] = objectClass.OID
+		if len(objectClass.Names) > 0 {
+			names[i] = objectClass.Names[0]
+		}
+	}
+	return names
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateEntry(t *testing.T) {
+	schema := testSchema(t)
+	tests := []struct {
+		name          string
+		dn            string
+		entry         map[string][]string
+		assumePresent func(attributeName string) bool
+		errs          []string
+	}{
+		{
+			name: "valid",
+			dn:   "cn=Jim Mit,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"top", "inetOrgPerson"},
+				"surname":     {"Mit"},
+				"mail":        {"jim.mit@example.com"},
+			},
+		},
+		{
+			name: "operational attribute",
+			dn:   "cn=Jim Mit,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"person"},
+				"sn":          {"Mit"},
+				"entryUUID":   {"597ae2f6-16a6-1027-98f4-d28b5365dc14"},
+			},
+		},
+		{
+			name: "auxiliary object class",
+			dn:   "uid=jim,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"inetOrgPerson", "posixAccount"},
+				"cn":          {"Jim Mit"},
+				"sn":          {"Mit"},
+				"uidNumber":   {"1000"},
+			},
+		},
+		{
+			name: "extensible object",
+			dn:   "cn=Jim Mit,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"person", "extensibleObject"},
+				"sn":          {"Mit"},
+				"mail":        {"jim.mit@example.com"},
+			},
+		},
+		{
+			name: "assumed present",
+			dn:   "uid=jim,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"inetOrgPerson"},
+				"cn":          {"Jim Mit"},
+			},
+			assumePresent: func(attributeName string) bool { return strings.EqualFold(attributeName, "sn") },
+		},
+		{
+			name: "missing object class",
+			dn:   "cn=Jim Mit,dc=example,dc=com",
+			entry: map[string][]string{
+				"sn": {"Mit"},
+			},
+			errs: []string{"attribute 'objectClass' is required"},
+		},
+		{
+			name: "undefined",
+			dn:   "cn=Jim Mit,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"person", "unknownClass"},
+				"sn":          {"Mit"},
+				"unknown":     {"value"},
+			},
+			errs: []string{"undefined attribute type 'unknown'", "undefined object class 'unknownClass'"},
+		},
+		{
+			name: "no structural object class",
+			dn:   "cn=Jim Mit,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"top", "posixAccount"},
+			},
+			errs: []string{"no structural object class in 'top', 'posixAccount'"},
+		},
+		{
+			name: "structural object classes not in one chain",
+			dn:   "cn=Jim Mit,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"person", "groupOfNames"},
+			},
+			errs: []string{"the structural object classes 'person', 'groupOfNames' do not form one chain"},
+		},
+		{
+			name: "required and not allowed attributes",
+			dn:   "uid=jim,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"person"},
+				"cn":          {"Jim Mit"},
+				"mail":        {"jim.mit@example.com"},
+			},
+			errs: []string{"attribute 'mail' is not allowed by the object classes", "attribute 'sn' is required by the object classes"},
+		},
+		{
+			name: "single-valued",
+			dn:   "uid=jim,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"inetOrgPerson", "posixAccount"},
+				"cn":          {"Jim Mit"},
+				"sn":          {"Mit"},
+				"uidNumber":   {"1000", "1001"},
+			},
+			errs: []string{"attribute 'uidNumber' is single-valued, but has 2 values"},
+		},
+		{
+			name: "DIT content rule",
+			dn:   "cn=admins,dc=example,dc=com",
+			entry: map[string][]string{
+				"objectClass": {"groupOfNames", "extensibleObject"},
+				"member":      {"cn=admin,dc=example,dc=com"},
+				"description": {"admins"},
+			},
+			errs: []string{
+				"attribute 'description' is precluded by the DIT content rule of 'groupOfNames'",
+				"auxiliary object class 'extensibleObject' is not allowed by the DIT content rule of 'groupOfNames'",
+			},
+		},
+	}
+	for _, test := range tests {
+		var errs []string
+		for _, err := range schema.ValidateEntry(&LdapEntry{Dn: test.dn, Entry: test.entry}, test.assumePresent) {
+			errs = append(errs, err.Error())
+		}
+		if !reflect.DeepEqual(errs, test.errs) {
+			t.Errorf("%s: expected %q, got %q", test.name, test.errs, errs)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// testResponse is a response of the testServer, a protocol operation with optional controls.
+type testResponse struct {
+	op       *ber.Packet
+	controls []ldap.Control
+}
+
+// testServer is a minimal LDAP server for unit tests. It accepts every bind and
+// answers the other requests with handle, which gets the number of the connection
+// (starting with 1), the protocol operation and the controls of the request.
+// The connection is closed, if handle returns no responses.
+type testServer struct {
+	address string
+
+	mutex       sync.Mutex
+	connections int
+	requests    map[ber.Tag]int
+}
+
+func newTestServer(t *testing.T, handle func(connection int, op *ber.Packet, controls []ldap.Control) []testResponse) *testServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &testServer{address: listener.Addr().String(), requests: make(map[ber.Tag]int)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mutex.Lock()
+			server.connections++
+			connection := server.connections
+			server.mutex.Unlock()
+			go server.serve(conn, connection, handle)
+		}
+	}()
+	return server
+}
+
+func (s *testServer) serve(conn net.Conn, connection int, handle func(connection int, op *ber.Packet, controls []ldap.Control) []testResponse) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value
+		op := packet.Children[1]
+		var controls []ldap.Control
+		if len(packet.Children) > 2 {
+			for _, child := range packet.Children[2].Children {
+				control, err := ldap.DecodeControl(child)
+				if err == nil {
+					controls = append(controls, control)
+				}
+			}
+		}
+
+		var responses []testResponse
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			responses = []testResponse{{op: testResult(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess)}}
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationAbandonRequest:
+			continue
+		default:
+			s.mutex.Lock()
+			s.requests[op.Tag]++
+			s.mutex.Unlock()
+			responses = handle(connection, op, controls)
+		}
+		if len(responses) == 0 {
+			return
+		}
+		for _, response := range responses {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+			envelope.AppendChild(response.op)
+			if len(response.controls) > 0 {
+				controls := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "Controls")
+				for _, control := range response.controls {
+					controls.AppendChild(control.Encode())
+				}
+				envelope.AppendChild(controls)
+			}
+			if _, err := conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// requestCount returns the number of requests with the application tag handled by the server.
+func (s *testServer) requestCount(tag ber.Tag) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests[tag]
+}
+
+// testResult returns an LDAPResult (RFC 4511) for the response with the application tag.
+func testResult(tag ber.Tag, resultCode uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, ldap.ApplicationMap[uint8(tag)])
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(resultCode), "resultCode"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return result
+}
+
+// testSearchResultEntry returns a search result entry with the dn and the attributes.
+func testSearchResultEntry(dn string, attributes map[string][]string) *ber.Packet {
+	entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "objectName"))
+	partialAttributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range attributes {
+		partialAttribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "partialAttribute")
+		partialAttribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+		for _, value := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+		}
+		partialAttribute.AppendChild(vals)
+		partialAttributes.AppendChild(partialAttribute)
+	}
+	entry.AppendChild(partialAttributes)
+	return entry
+}
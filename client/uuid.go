@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const attributeNameObjectGUID = "objectGUID"
+
+// UUIDAttributeNames lists the attributes holding the stable identifier of an entry:
+// entryUUID (RFC 4530, e.g. OpenLDAP), nsUniqueId (389 Directory Server) and objectGUID (Active Directory).
+var UUIDAttributeNames = []string{"entryUUID", "nsUniqueId", attributeNameObjectGUID}
+
+// GetEntryUUID returns the stable identifier of ldapEntry or "" if the entry has none.
+// The binary objectGUID is returned in the usual string representation of GUIDs.
+func GetEntryUUID(ldapEntry *LdapEntry) string {
+	for _, uuidAttributeName := range UUIDAttributeNames {
+		for attributeName, attributeValues := range ldapEntry.Entry {
+			if !strings.EqualFold(attributeName, uuidAttributeName) || len(attributeValues) != 1 {
+				continue
+			}
+			if uuidAttributeName == attributeNameObjectGUID {
+				return formatGUID([]byte(attributeValues[0]))
+			}
+			return attributeValues[0]
+		}
+	}
+	return ""
+}
+
+// ReadEntryByUUID searches all naming contexts of the server for the entry with the stable identifier uuid.
+func (c *Client) ReadEntryByUUID(
+	ctx context.Context,
+	uuid string,
+	attributes *[]string,
+) (ldapEntry *LdapEntry, err error) {
+	rootDSE, err := c.ReadRootDSE(ctx, &[]string{"namingContexts"})
+	if err != nil {
+		return nil, err
+	}
+
+	filter := "(|"
+	for _, uuidAttributeName := range UUIDAttributeNames {
+		if uuidAttributeName == attributeNameObjectGUID {
+			if guid, err := parseGUID(uuid); err == nil {
+				filter += "(" + uuidAttributeName + "=" + escapeFilterBytes(guid) + ")"
+			}
+			continue
+		}
+		filter += "(" + uuidAttributeName + "=" + ldap.EscapeFilter(uuid) + ")"
+	}
+	filter += ")"
+
+	for _, namingContext := range rootDSE.Entry["namingContexts"] {
+		ldapEntry, err = c.ReadEntryByFilter(ctx, namingContext, filter, attributes)
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			continue
+		}
+		return ldapEntry, err
+	}
+
+	return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, fmt.Errorf("the uuid '%s' doesn't match any entry", uuid))
+}
+
+// ReadRootDSE reads the attributes of the root DSE of the server.
+func (c *Client) ReadRootDSE(
+	ctx context.Context,
+	attributes *[]string,
+) (ldapEntry *LdapEntry, err error) {
+	req := ldap.NewSearchRequest(
+		"",
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		c.SearchTimeLimit,
+		false,
+		"(objectClass=*)",
+		*attributes,
+		[]ldap.Control{},
+	)
+
+	var searchResult *ldap.SearchResult
+	err = c.do(ctx, false, true, func(conn *ldap.Conn) (err error) {
+		searchResult, err = conn.Search(req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(searchResult.Entries) != 1 {
+		return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("the root DSE couldn't be read"))
+	}
+
+	ldapEntry = new(LdapEntry)
+	ldapEntry.Entry = make(map[string][]string)
+
+	for _, attr := range searchResult.Entries[0].Attributes {
+		ldapEntry.Entry[attr.Name] = attr.Values
+	}
+
+	return ldapEntry, nil
+}
+
+// formatGUID formats the little endian binary GUID as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func formatGUID(guid []byte) string {
+	if len(guid) != 16 {
+		return hex.EncodeToString(guid)
+	}
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(guid[0:4]),
+		binary.LittleEndian.Uint16(guid[4:6]),
+		binary.LittleEndian.Uint16(guid[6:8]),
+		guid[8:10],
+		guid[10:16],
+	)
+}
+
+// parseGUID parses the string representation of a GUID into its little endian binary form.
+func parseGUID(s string) ([]byte, error) {
+	decoded, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != 16 || len(s) != 36 {
+		return nil, fmt.Errorf("'%s' is not a GUID", s)
+	}
+	guid := make([]byte, 16)
+	binary.LittleEndian.PutUint32(guid[0:4], binary.BigEndian.Uint32(decoded[0:4]))
+	binary.LittleEndian.PutUint16(guid[4:6], binary.BigEndian.Uint16(decoded[4:6]))
+	binary.LittleEndian.PutUint16(guid[6:8], binary.BigEndian.Uint16(decoded[6:8]))
+	copy(guid[8:], decoded[8:])
+	return guid, nil
+}
+
+// escapeFilterBytes escapes every byte of value for use in a search filter.
+func escapeFilterBytes(value []byte) string {
+	var escaped strings.Builder
+	for _, b := range value {
+		fmt.Fprintf(&escaped, "\\%02x", b)
+	}
+	return escaped.String()
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGUID(t *testing.T) {
+	// objectGUID as stored by Active Directory, the first three groups are little endian
+	guid := []byte{0x78, 0x56, 0x34, 0x12, 0xbc, 0x9a, 0xf0, 0xde, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	s := "12345678-9abc-def0-0123-456789abcdef"
+
+	if formatted := formatGUID(guid); formatted != s {
+		t.Errorf("formatGUID: expected %q, got %q", s, formatted)
+	}
+	parsed, err := parseGUID(s)
+	if err != nil {
+		t.Fatalf("parseGUID(%q): %s", s, err)
+	}
+	if !bytes.Equal(parsed, guid) {
+		t.Errorf("parseGUID(%q): expected %x, got %x", s, guid, parsed)
+	}
+	if parsed, err := parseGUID("12345678-9ABC-DEF0-0123-456789ABCDEF"); err != nil || !bytes.Equal(parsed, guid) {
+		t.Errorf("parseGUID of upper case: expected %x, got %x, %v", guid, parsed, err)
+	}
+}
+
+func TestParseGUIDInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"12345678",
+		"123456789abcdef00123456789abcdef",
+		"12345678-9abc-def0-0123-456789abcdeg",
+		"12345678-9abc-def0-0123-456789abcdef00",
+	} {
+		if _, err := parseGUID(s); err == nil {
+			t.Errorf("parseGUID(%q): expected an error", s)
+		}
+	}
+}
+
+func TestGetEntryUUID(t *testing.T) {
+	tests := []struct {
+		entry    map[string][]string
+		expected string
+	}{
+		{map[string][]string{"entryUUID": {"597ae2f6-16a6-1027-98f4-d28b5365dc14"}}, "597ae2f6-16a6-1027-98f4-d28b5365dc14"},
+		{map[string][]string{"nsuniqueid": {"66446001-1dd211b2-8e13d4c0-5a0c0000"}}, "66446001-1dd211b2-8e13d4c0-5a0c0000"},
+		{map[string][]string{"objectGUID": {string([]byte{0x78, 0x56, 0x34, 0x12, 0xbc, 0x9a, 0xf0, 0xde, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef})}}, "12345678-9abc-def0-0123-456789abcdef"},
+		{map[string][]string{"cn": {"Jim Mit"}}, ""},
+	}
+	for _, test := range tests {
+		if uuid := GetEntryUUID(&LdapEntry{Entry: test.entry}); uuid != test.expected {
+			t.Errorf("GetEntryUUID(%v): expected %q, got %q", test.entry, test.expected, uuid)
+		}
+	}
+}
+
+func TestEscapeFilterBytes(t *testing.T) {
+	if escaped := escapeFilterBytes([]byte{0x00, 0x2a, 0xff}); escaped != `\00\2a\ff` {
+		t.Errorf("escapeFilterBytes: expected %q, got %q", `\00\2a\ff`, escaped)
+	}
+}
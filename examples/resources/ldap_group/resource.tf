@@ -0,0 +1,22 @@
+resource "ldap_entry" "groups_example_com" {
+  dn = "ou=groups,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_group" "admins" {
+  dn          = "cn=admins,${ldap_entry.groups_example_com.dn}"
+  description = "administrators"
+  members = [
+    "uid=jimmit01,ou=users,dc=example,dc=com",
+  ]
+}
+
+resource "ldap_group" "developers" {
+  dn              = "cn=developers,${ldap_entry.groups_example_com.dn}"
+  object_class    = "posixGroup"
+  gid_number      = 10001
+  membership_mode = "additive"
+  members         = ["jimmit01"]
+}
@@ -0,0 +1,12 @@
+resource "ldap_group" "admins" {
+  dn              = "cn=admins,ou=groups,dc=example,dc=com"
+  membership_mode = "additive"
+  members = [
+    "uid=jimmit01,ou=users,dc=example,dc=com",
+  ]
+}
+
+resource "ldap_group_member" "admins_jimmit02" {
+  group_dn = ldap_group.admins.dn
+  member   = "uid=jimmit02,ou=users,dc=example,dc=com"
+}
@@ -6,11 +6,50 @@ const attributeNameIgnoreAttributePatterns = "ignore_attribute_patterns"
 const attributeNameRestrictAttributes = "restrict_attributes"
 const attributeNameBase64EncodeAttributes = "base64encode_attributes"
 const attributeNameBase64EncodeAttributePatterns = "base64encode_attribute_patterns"
+const attributeNameNoBase64EncodeAttributes = "no_base64encode_attributes"
 const attributeNameDataJson = "data_json"
 const attributeNameDn = "dn"
 const attributeNameOu = "ou"
 const attributeNameCaseSensitiveAttibuteNames = "case_sensitive_attribute_names"
 const attributeNamePagingSize = "paging_size"
 const attributeNameDataJsonCreateDefaults = "data_json_create_defaults"
+const attributeNameProxiedAuthz = "proxied_authz"
+const attributeNameEntryUUID = "entry_uuid"
+const attributeNameDeleteSubtree = "delete_subtree"
+const attributeNameDeletionProtection = "deletion_protection"
+const attributeNameOnDestroy = "on_destroy"
+const attributeNameDeletedContainer = "deleted_container"
+const attributeNameModifyStrategy = "modify_strategy"
+const attributeNameAssertUnchanged = "assert_unchanged"
+const attributeNameRevision = "revision"
+const attributeNameAttributes = "attributes"
+const attributeNameName = "name"
+const attributeNameValues = "values"
+const attributeNameValidateSchema = "validate_schema"
+const attributeNameObjectClass = "object_class"
+const attributeNameMembers = "members"
+const attributeNameMembershipMode = "membership_mode"
+const attributeNameGidNumber = "gid_number"
+const attributeNameDescription = "description"
+const attributeNameGroupDn = "group_dn"
+const attributeNameMember = "member"
+const attributeNameMemberAttribute = "member_attribute"
+
+const onDestroyDelete = "delete"
+const onDestroyMove = "move"
+
+const membershipModeAuthoritative = "authoritative"
+const membershipModeAdditive = "additive"
+
+const objectClassGroupOfNames = "groupOfNames"
+const objectClassGroupOfUniqueNames = "groupOfUniqueNames"
+const objectClassPosixGroup = "posixGroup"
+
+// groupMemberAttributeNames are the attributes holding the members of the object classes of groups.
+var groupMemberAttributeNames = map[string]string{
+	objectClassGroupOfNames:       "member",
+	objectClassGroupOfUniqueNames: "uniqueMember",
+	objectClassPosixGroup:         "memberUid",
+}
 
 const dummyFilter = "objectClass=*"
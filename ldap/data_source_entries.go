@@ -32,6 +32,12 @@ func dataSourceLDAPEntries() *schema.Resource {
 							Type:        schema.TypeString,
 							Computed:    true,
 						},
+						attributeNameAttributes: {
+							Description: "the attributes of the entry with their values that are read",
+							Type:        schema.TypeSet,
+							Computed:    true,
+							Elem:        attributesElem(),
+						},
 					},
 				},
 			},
@@ -75,6 +81,12 @@ func dataSourceLDAPEntries() *schema.Resource {
 				Optional:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
+			attributeNameNoBase64EncodeAttributes: {
+				Description: "list of attributes not to be encoded to base64, although they are binary according to the schema of the LDAP server",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 			attributeNamePagingSize: {
 				Description: "Desired page size for the search request. Use 0 to retrieve all results without pagination, or a value greater than 0 to enable paginated queries. Defaults to 0.",
 				Type:        schema.TypeInt,
@@ -86,7 +98,7 @@ func dataSourceLDAPEntries() *schema.Resource {
 	}
 }
 
-func dataSourceLDAPEntriesRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func dataSourceLDAPEntriesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cl := m.(*client.Client)
 
 	ou := d.Get(attributeNameOu).(string)
@@ -102,9 +114,9 @@ func dataSourceLDAPEntriesRead(_ context.Context, d *schema.ResourceData, m inte
 
 	restrictAttributes = getAttributeListFromAttribute(d, attributeNameRestrictAttributes)
 
-	ldapEntries, err := cl.ReadEntriesByFilter(ou, "("+filter+")", restrictAttributes, pagingSize)
+	ldapEntries, err := cl.ReadEntriesByFilter(ctx, ou, "("+filter+")", restrictAttributes, pagingSize, ldap.ScopeWholeSubtree)
 	if err != nil {
-		if err.(*ldap.Error).ResultCode != ldap.LDAPResultNoSuchObject {
+		if !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
 			return diag.FromErr(err)
 		}
 		err = nil
@@ -114,6 +126,10 @@ func dataSourceLDAPEntriesRead(_ context.Context, d *schema.ResourceData, m inte
 	d.SetId(id)
 
 	ignoreAndBase64Encode := getIgnoreAndBase64encode(d)
+	err = base64encodeBinaryAttributes(ctx, cl, ignoreAndBase64Encode)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	entriesList := []interface{}{}
 	if ldapEntries != nil {
 		for _, ldapEntry := range *ldapEntries {
@@ -123,8 +139,9 @@ func dataSourceLDAPEntriesRead(_ context.Context, d *schema.ResourceData, m inte
 				return diag.Errorf("error marshaling JSON for %q: %s", id, err)
 			}
 			values := map[string]interface{}{
-				attributeNameDn:       ldapEntry.Dn,
-				attributeNameDataJson: string(jsonData),
+				attributeNameDn:         ldapEntry.Dn,
+				attributeNameDataJson:   string(jsonData),
+				attributeNameAttributes: flattenAttributes(ldapEntry.Entry),
 			}
 			entriesList = append(entriesList, values)
 		}
@@ -39,6 +39,12 @@ func dataSourceLDAPEntry() *schema.Resource {
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			attributeNameAttributes: {
+				Description: "the attributes of the entry with their values that are read",
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        attributesElem(),
+			},
 			attributeNameIgnoreAttributes: {
 				Description: "list of attributes to ignore",
 				Type:        schema.TypeList,
@@ -69,11 +75,17 @@ func dataSourceLDAPEntry() *schema.Resource {
 				Optional:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
+			attributeNameNoBase64EncodeAttributes: {
+				Description: "list of attributes not to be encoded to base64, although they are binary according to the schema of the LDAP server",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 		},
 	}
 }
 
-func dataSourceLDAPEntryRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func dataSourceLDAPEntryRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cl := m.(*client.Client)
 
 	var ok bool
@@ -96,17 +108,17 @@ func dataSourceLDAPEntryRead(_ context.Context, d *schema.ResourceData, m interf
 		restrictAttributes = getAttributeListFromAttribute(d, attributeNameRestrictAttributes)
 	}
 
-	ldapEntry, err := cl.ReadEntryByFilter(baseDn, "("+filter+")", restrictAttributes)
+	ldapEntry, err := cl.ReadEntryByFilter(ctx, baseDn, "("+filter+")", restrictAttributes)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	ignoreAndBase64Encode := getIgnoreAndBase64encode(d)
-	client.IgnoreAndBase64encodeAttributes(ldapEntry, ignoreAndBase64Encode)
-
+	err = base64encodeBinaryAttributes(ctx, cl, ignoreAndBase64Encode)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	client.IgnoreAndBase64encodeAttributes(ldapEntry, ignoreAndBase64Encode)
 
 	id := ldapEntry.Dn
 	d.SetId(id)
@@ -125,5 +137,9 @@ func dataSourceLDAPEntryRead(_ context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
+	if err := d.Set(attributeNameAttributes, flattenAttributes(ldapEntry.Entry)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return diag.FromErr(err)
 }
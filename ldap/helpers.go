@@ -1,10 +1,17 @@
 package ldap
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/l-with/terraform-provider-ldap/client"
+	"golang.org/x/exp/slices"
 )
 
 func getIgnoreAndBase64encode(d *schema.ResourceData) (ignoreAndBas64Encode *client.IgnoreAndBase64Encode) {
@@ -13,6 +20,7 @@ func getIgnoreAndBase64encode(d *schema.ResourceData) (ignoreAndBas64Encode *cli
 	ignoreAndBas64Encode.IgnoreAttributePatterns = getAttributeListFromAttribute(d, attributeNameIgnoreAttributePatterns)
 	ignoreAndBas64Encode.Base64encodeAttributes = getAttributeListFromAttribute(d, attributeNameBase64EncodeAttributes)
 	ignoreAndBas64Encode.Base64encodeAttributePatterns = getAttributeListFromAttribute(d, attributeNameBase64EncodeAttributePatterns)
+	ignoreAndBas64Encode.NoBase64encodeAttributes = getAttributeListFromAttribute(d, attributeNameNoBase64EncodeAttributes)
 	return ignoreAndBas64Encode
 }
 
@@ -30,6 +38,7 @@ func getOldIgnoreAndBase64encode(d *schema.ResourceData) (oldIgnoreAndBas64Encod
 	oldIgnoreAndBas64Encode.IgnoreAttributePatterns = getOldAttributeListFromAttribute(d, attributeNameIgnoreAttributePatterns)
 	oldIgnoreAndBas64Encode.Base64encodeAttributes = getOldAttributeListFromAttribute(d, attributeNameBase64EncodeAttributes)
 	oldIgnoreAndBas64Encode.Base64encodeAttributePatterns = getOldAttributeListFromAttribute(d, attributeNameBase64EncodeAttributePatterns)
+	oldIgnoreAndBas64Encode.NoBase64encodeAttributes = getOldAttributeListFromAttribute(d, attributeNameNoBase64EncodeAttributes)
 	return oldIgnoreAndBas64Encode
 }
 
@@ -98,3 +107,257 @@ func appendOldCreateDefaultKeysToIgnore(ig *client.IgnoreAndBase64Encode, d *sch
 		*ig.IgnoreAttributes = append(*ig.IgnoreAttributes, k)
 	}
 }
+
+// containsFold reports whether values contains value, ignoring case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// assertionFilter returns the filter asserting the revision of an entry, "" if the revision is unknown.
+func assertionFilter(revision string) string {
+	if revision == "" {
+		return ""
+	}
+	return "(" + revision + ")"
+}
+
+// attributesElem is the schema of the elements of attributes, an attribute name with its values.
+func attributesElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			attributeNameName: {
+				Description: "name of the attribute",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			attributeNameValues: {
+				Description: "values of the attribute",
+				Type:        schema.TypeSet,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// flattenAttributes converts the values of the attributes of an entry to the elements of attributes.
+func flattenAttributes(entry map[string][]string) []interface{} {
+	attributeNames := make([]string, 0, len(entry))
+	for attributeName := range entry {
+		attributeNames = append(attributeNames, attributeName)
+	}
+	sort.Strings(attributeNames)
+
+	attributes := make([]interface{}, 0, len(entry))
+	for _, attributeName := range attributeNames {
+		values := make([]interface{}, 0, len(entry[attributeName]))
+		for _, value := range entry[attributeName] {
+			values = append(values, value)
+		}
+		attributes = append(attributes, map[string]interface{}{
+			attributeNameName:   attributeName,
+			attributeNameValues: values,
+		})
+	}
+	return attributes
+}
+
+// expandAttributes converts the elements of attributes to the values of the attributes of an entry.
+func expandAttributes(attributes interface{}) map[string][]string {
+	entry := make(map[string][]string)
+	attributeSet, ok := attributes.(*schema.Set)
+	if !ok {
+		return entry
+	}
+	for _, attribute := range attributeSet.List() {
+		attributeMap := attribute.(map[string]interface{})
+		var values []string
+		for _, value := range attributeMap[attributeNameValues].(*schema.Set).List() {
+			values = append(values, value.(string))
+		}
+		entry[attributeMap[attributeNameName].(string)] = values
+	}
+	return entry
+}
+
+// attributesNames returns the names in the elements of attributes.
+func attributesNames(attributes interface{}) []string {
+	var names []string
+	for name := range expandAttributes(attributes) {
+		names = append(names, name)
+	}
+	return names
+}
+
+// attributesConfigured reports whether the entry is configured by attributes instead of data_json.
+func attributesConfigured(d *schema.ResourceData) bool {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return false
+	}
+	return !rawConfig.GetAttr(attributeNameAttributes).IsNull()
+}
+
+// getEntry returns the values of the attributes configured by attributes or data_json.
+func getEntry(d *schema.ResourceData) (map[string][]string, error) {
+	if attributesConfigured(d) {
+		return expandAttributes(d.Get(attributeNameAttributes)), nil
+	}
+	entry := make(map[string][]string)
+	err := json.Unmarshal([]byte(d.Get(attributeNameDataJson).(string)), &entry)
+	return entry, err
+}
+
+// getEntryChange returns the old and the new values of the attributes configured by attributes or data_json.
+func getEntryChange(d *schema.ResourceData) (oldEntry map[string][]string, newEntry map[string][]string, err error) {
+	if attributesConfigured(d) {
+		oldAttributes, newAttributes := d.GetChange(attributeNameAttributes)
+		return expandAttributes(oldAttributes), expandAttributes(newAttributes), nil
+	}
+	oldDataJson, newDataJson := d.GetChange(attributeNameDataJson)
+	oldEntry = make(map[string][]string)
+	err = json.Unmarshal([]byte(oldDataJson.(string)), &oldEntry)
+	if err != nil {
+		return nil, nil, err
+	}
+	newEntry = make(map[string][]string)
+	err = json.Unmarshal([]byte(newDataJson.(string)), &newEntry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldEntry, newEntry, nil
+}
+
+// base64encodeBinaryAttributes makes ignoreAndBase64Encode base64 encode the attributes which are binary
+// according to the schema of the server in addition to the listed attributes.
+// If the server doesn't provide its schema, only attributes with the option ";binary" are detected.
+// A transient error reading the schema is returned, as detecting the attributes without the schema
+// would encode them differently than before.
+func base64encodeBinaryAttributes(ctx context.Context, cl *client.Client, ignoreAndBase64Encode *client.IgnoreAndBase64Encode) error {
+	ldapSchema, err := cl.Schema(ctx)
+	if client.IsTransientError(err) {
+		return fmt.Errorf("reading the schema of the server for detecting binary attributes failed: %w", err)
+	}
+	if err != nil {
+		tflog.Warn(ctx, "detecting binary attributes without the schema of the server", map[string]interface{}{"error": err.Error()})
+		ignoreAndBase64Encode.Base64encodeAttributeFunc = client.HasBinaryOption
+		return nil
+	}
+	ignoreAndBase64Encode.Base64encodeAttributeFunc = ldapSchema.IsBinary
+	return nil
+}
+
+// renameAttributes returns the attributes of entry with the names which denote the same attributes.
+// The values of attributes which are renamed to the same name are merged.
+func renameAttributes(entry map[string][]string, names []string, sameAttribute func(name string, otherName string) bool) map[string][]string {
+	attributeNames := make([]string, 0, len(entry))
+	for attributeName := range entry {
+		attributeNames = append(attributeNames, attributeName)
+	}
+	// the values are merged in a stable order
+	sort.Strings(attributeNames)
+
+	renamedEntry := make(map[string][]string, len(entry))
+	for _, attributeName := range attributeNames {
+		newName := attributeName
+		if !slices.Contains(names, attributeName) {
+			for _, name := range names {
+				if sameAttribute(attributeName, name) {
+					newName = name
+					break
+				}
+			}
+		}
+		renamedEntry[newName] = append(renamedEntry[newName], entry[attributeName]...)
+	}
+	return renamedEntry
+}
+
+// sameAttributeFunc returns the function reporting whether two attribute names denote the same attribute
+// according to the schema of the server. Without the schema the names are compared ignoring case,
+// if entry_attribute_names_case_sensitive is false and they are not in case_sensitive_attribute_names.
+func sameAttributeFunc(ctx context.Context, d *schema.ResourceData, cl *client.Client) func(name string, otherName string) bool {
+	ldapSchema, err := cl.Schema(ctx)
+	if err == nil {
+		return ldapSchema.SameAttribute
+	}
+	tflog.Warn(ctx, "comparing attribute names without the schema of the server", map[string]interface{}{"error": err.Error()})
+
+	if cl.EntryAttributeNamesCaseSensitive {
+		return func(name string, otherName string) bool {
+			return name == otherName
+		}
+	}
+	caseSensitiveAttributeNames := *getAttributeListFromAttribute(d, attributeNameCaseSensitiveAttibuteNames)
+	return func(name string, otherName string) bool {
+		if containsFold(caseSensitiveAttributeNames, name) {
+			return name == otherName
+		}
+		return strings.EqualFold(name, otherName)
+	}
+}
+
+// lowerAttributeNames returns the entry with the names of its attributes in lower case,
+// except for the caseSensitiveAttributeNames.
+func lowerAttributeNames(entry map[string][]string, caseSensitiveAttributeNames []string) map[string][]string {
+	lowerEntry := make(map[string][]string, len(entry))
+	for attributeName, values := range entry {
+		if !containsFold(caseSensitiveAttributeNames, attributeName) {
+			attributeName = strings.ToLower(attributeName)
+		}
+		lowerEntry[attributeName] = values
+	}
+	return lowerEntry
+}
+
+// valueEqualFunc returns the function reporting whether two values of the attribute match each other
+// according to the schema of the server. Without the schema DNs are compared ignoring case
+// and other values are compared exactly.
+func valueEqualFunc(ctx context.Context, cl *client.Client, attributeName string) func(value string, otherValue string) bool {
+	ldapSchema, err := cl.Schema(ctx)
+	if err == nil {
+		return func(value string, otherValue string) bool {
+			return ldapSchema.NormalizeValue(attributeName, value) == ldapSchema.NormalizeValue(attributeName, otherValue)
+		}
+	}
+	tflog.Warn(ctx, "comparing values without the schema of the server", map[string]interface{}{"error": err.Error()})
+
+	return func(value string, otherValue string) bool {
+		if value == otherValue {
+			return true
+		}
+		dn, err := ldap.ParseDN(value)
+		if err != nil {
+			return false
+		}
+		otherDn, err := ldap.ParseDN(otherValue)
+		if err != nil {
+			return false
+		}
+		return dn.EqualFold(otherDn)
+	}
+}
+
+// matchValues splits values into the values matching one of the configuredValues, which are returned
+// as configured, and the other values.
+func matchValues(values []string, configuredValues []string, equal func(value string, otherValue string) bool) (matchedValues []string, otherValues []string) {
+	for _, value := range values {
+		matched := false
+		for _, configuredValue := range configuredValues {
+			if equal(value, configuredValue) {
+				matchedValues = append(matchedValues, configuredValue)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			otherValues = append(otherValues, value)
+		}
+	}
+	return matchedValues, otherValues
+}
@@ -0,0 +1,40 @@
+package ldap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenameAttributes(t *testing.T) {
+	aliases := [][]string{{"cn", "commonName"}, {"sn", "surname"}}
+	sameAttribute := func(name string, otherName string) bool {
+		for _, names := range aliases {
+			if containsFold(names, name) && containsFold(names, otherName) {
+				return true
+			}
+		}
+		return strings.EqualFold(name, otherName)
+	}
+	entry := map[string][]string{
+		"commonName":  {"Jim Mit"},
+		"CN":          {"Jim"},
+		"SN":          {"Mit"},
+		"objectClass": {"inetOrgPerson"},
+		"mail":        {"jim.mit@example.com"},
+	}
+	renamedEntry := renameAttributes(entry, []string{"cn", "surname", "objectclass"}, sameAttribute)
+
+	expected := map[string][]string{
+		"cn":          {"Jim", "Jim Mit"},
+		"surname":     {"Mit"},
+		"objectclass": {"inetOrgPerson"},
+		"mail":        {"jim.mit@example.com"},
+	}
+	if !reflect.DeepEqual(renamedEntry, expected) {
+		t.Errorf("expected %v, got %v", expected, renamedEntry)
+	}
+	if len(entry) != 5 || len(entry["commonName"]) != 1 || len(entry["CN"]) != 1 {
+		t.Errorf("expected the entry to be unchanged, got %v", entry)
+	}
+}
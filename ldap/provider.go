@@ -2,50 +2,180 @@ package ldap
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	client2 "github.com/l-with/terraform-provider-ldap/client"
 )
 
+const attributeNameUrl = "url"
+const ldapUrlEnvVarName = "LDAP_URL"
+const attributeNameUrls = "urls"
+const attributeNameRandomizeUrls = "randomize_urls"
+const attributeNameWriteUrl = "write_url"
 const attributeNameHost = "host"
 const ldapHostEnvVarName = "LDAP_HOST"
 const attributeNamePort = "port"
 const ldapPortEnvVarName = "LDAP_PORT"
+const attributeNameBindMethod = "bind_method"
 const attributeNameBindUser = "bind_user"
 const ldapBindUserEnvVarName = "LDAP_BIND_USER"
 const attributeNameBindPassword = "bind_password"
 const ldapBindPasswordEnvVarName = "LDAP_BIND_PASSWORD"
+const attributeNameBindPasswordFile = "bind_password_file"
+const ldapBindPasswordFileEnvVarName = "LDAP_BIND_PASSWORD_FILE"
+const attributeNameBindPasswordCommand = "bind_password_command"
+const attributeNameNtlmDomain = "ntlm_domain"
+const attributeNameNtlmHash = "ntlm_hash"
+const attributeNameKerberosRealm = "kerberos_realm"
+const attributeNameKerberosKeytab = "kerberos_keytab"
+const attributeNameKerberosCCache = "kerberos_ccache"
+const attributeNameKerberosConfig = "kerberos_config"
+const krb5ConfigEnvVarName = "KRB5_CONFIG"
+const attributeNameKerberosSpn = "kerberos_spn"
 const attributeNameTls = "tls"
+const attributeNameStartTls = "start_tls"
 const attributeNameTlsInsecure = "tls_insecure"
+const attributeNameTlsCACertificate = "tls_ca_certificate"
+const attributeNameTlsCACertificateFile = "tls_ca_certificate_file"
+const attributeNameTlsClientCertificate = "tls_client_certificate"
+const attributeNameTlsClientCertificateFile = "tls_client_certificate_file"
+const attributeNameTlsClientKey = "tls_client_key"
+const attributeNameTlsClientKeyFile = "tls_client_key_file"
+const attributeNameTlsServerName = "tls_server_name"
+const attributeNameTlsMinVersion = "tls_min_version"
+const attributeNameMaxConnections = "max_connections"
+const attributeNameDialTimeout = "dial_timeout"
+const attributeNameRequestTimeout = "request_timeout"
+const attributeNameSearchTimeLimit = "search_time_limit"
+const attributeNameSearchSizeLimit = "search_size_limit"
+const attributeNameMaxRetries = "max_retries"
+const attributeNameRetryBackoff = "retry_backoff"
+const attributeNameProtectedDns = "protected_dns"
 const attributeEntryAttributeNamesCaseSensitive = "entry_attribute_names_case_sensitive"
 
 func Provider() *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
+			attributeNameUrl: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc(ldapUrlEnvVarName, nil),
+				ConflictsWith: []string{attributeNameUrls, attributeNameHost, attributeNamePort},
+				Description: "LDAP URL (`ldap://host:port`, `ldaps://host:port` or `ldapi://%2Fvar%2Frun%2Fslapd%2Fldapi` for a Unix domain socket) as alternative to `" + attributeNameHost + "` and `" + attributeNamePort + "`, " +
+					"can optionally be passed as `" + ldapUrlEnvVarName + "`environment variable. The scheme takes precedence over `" + attributeNameTls + "`.",
+			},
+			attributeNameUrls: {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{attributeNameUrl, attributeNameHost, attributeNamePort},
+				Description:   "list of LDAP URLs (s. `" + attributeNameUrl + "`) which are tried in order until a connection can be established and bound",
+			},
+			attributeNameRandomizeUrls: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Try the servers of `" + attributeNameUrls + "` in random order. Default is `false`.",
+			},
+			attributeNameWriteUrl: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "LDAP URL of the preferred server for resource operations (falls back to `" + attributeNameUrls + "`), data sources read from `" + attributeNameUrls + "`",
+			},
 			attributeNameHost: {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc(ldapHostEnvVarName, nil),
-				Description: "LDAP host, can optionally be passed as `" + ldapHostEnvVarName + "`environment variable",
+				Description: "LDAP host, can optionally be passed as `" + ldapHostEnvVarName + "`environment variable, required if `" + attributeNameUrl + "` is not set",
 			},
 			attributeNamePort: {
 				Type:        schema.TypeInt,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc(ldapPortEnvVarName, nil),
-				Description: "LDAP port, can optionally be passed as `" + ldapPortEnvVarName + "`environment variable",
+				Description: "LDAP port, can optionally be passed as `" + ldapPortEnvVarName + "`environment variable, required if `" + attributeNameUrl + "` is not set",
+			},
+			attributeNameBindMethod: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          client2.BindMethodSimple,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(client2.BindMethods, false)),
+				Description: "LDAP bind method, one of `" + client2.BindMethodSimple + "` (bind DN and password), `" + client2.BindMethodExternal + "` (SASL EXTERNAL, e.g. with the TLS client certificate), " +
+					"`" + client2.BindMethodAnonymous + "`, `" + client2.BindMethodUnauthenticated + "` (bind DN without password), " +
+					"`" + client2.BindMethodNTLM + "` (user and password or `" + attributeNameNtlmHash + "`) or `" + client2.BindMethodGSSAPI + "` (SASL GSSAPI with Kerberos). Default is `" + client2.BindMethodSimple + "`.",
 			},
 			attributeNameBindUser: {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc(ldapBindUserEnvVarName, nil),
-				Description: "LDAP username, can optionally be passed as `" + ldapBindUserEnvVarName + "`environment variable",
+				Description: "LDAP username, can optionally be passed as `" + ldapBindUserEnvVarName + "`environment variable, required for bind method `" + client2.BindMethodSimple + "`, `" + client2.BindMethodUnauthenticated + "` and `" + client2.BindMethodNTLM + "`. " +
+					"For bind method `" + client2.BindMethodGSSAPI + "` the Kerberos principal name (without realm).",
 			},
 			attributeNameBindPassword: {
+				Type:          schema.TypeString,
+				DefaultFunc:   schema.EnvDefaultFunc(ldapBindPasswordEnvVarName, nil),
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{attributeNameBindPasswordFile, attributeNameBindPasswordCommand},
+				Description:   "LDAP password, can optionally be passed as `" + ldapBindPasswordEnvVarName + "`environment variable, required for bind method `" + client2.BindMethodSimple + "` unless `" + attributeNameBindPasswordFile + "` or `" + attributeNameBindPasswordCommand + "` is used",
+			},
+			attributeNameBindPasswordFile: {
+				Type:          schema.TypeString,
+				DefaultFunc:   schema.EnvDefaultFunc(ldapBindPasswordFileEnvVarName, nil),
+				Optional:      true,
+				ConflictsWith: []string{attributeNameBindPassword, attributeNameBindPasswordCommand},
+				Description:   "Path to a file containing the LDAP password (trailing newlines are removed), can optionally be passed as `" + ldapBindPasswordFileEnvVarName + "`environment variable. The file is read for each bind, so a rotated password is used for new connections.",
+			},
+			attributeNameBindPasswordCommand: {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{attributeNameBindPassword, attributeNameBindPasswordFile},
+				Description:   "Command and arguments (executed without shell) printing the LDAP password to stdout (trailing newlines are removed). The command is run for each bind, so a rotated password is used for new connections.",
+			},
+			attributeNameNtlmDomain: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Domain for bind method `" + client2.BindMethodNTLM + "`, defaults to the domain sent by the server",
+			},
+			attributeNameNtlmHash: {
 				Type:        schema.TypeString,
-				DefaultFunc: schema.EnvDefaultFunc(ldapBindPasswordEnvVarName, nil),
-				Required:    true,
-				Description: "LDAP password, can optionally be passed as `" + ldapBindPasswordEnvVarName + "`environment variable",
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Hex encoded NT hash used instead of the password for bind method `" + client2.BindMethodNTLM + "`",
+			},
+			attributeNameKerberosRealm: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Kerberos realm of `" + attributeNameBindUser + "` for bind method `" + client2.BindMethodGSSAPI + "`",
+			},
+			attributeNameKerberosKeytab: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{attributeNameKerberosCCache},
+				Description:   "Path to the keytab of `" + attributeNameBindUser + "` for bind method `" + client2.BindMethodGSSAPI + "`, if neither keytab nor credential cache is set the bind password is used",
+			},
+			attributeNameKerberosCCache: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{attributeNameKerberosKeytab},
+				Description:   "Path to the Kerberos credential cache (e.g. from `kinit`) for bind method `" + client2.BindMethodGSSAPI + "`",
+			},
+			attributeNameKerberosConfig: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(krb5ConfigEnvVarName, "/etc/krb5.conf"),
+				Description: "Path to the Kerberos configuration for bind method `" + client2.BindMethodGSSAPI + "`, can optionally be passed as `" + krb5ConfigEnvVarName + "`environment variable. Default is `/etc/krb5.conf`.",
+			},
+			attributeNameKerberosSpn: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Service principal name of the LDAP server for bind method `" + client2.BindMethodGSSAPI + "`. Defaults to `ldap/<host>`.",
 			},
 			attributeNameTls: {
 				Type:        schema.TypeBool,
@@ -53,21 +183,141 @@ func Provider() *schema.Provider {
 				Default:     false,
 				Description: "Enable the TLS encryption for LDAP (LDAPS). Default, is `false`.",
 			},
+			attributeNameStartTls: {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{attributeNameTls},
+				Description:   "Upgrade the plain LDAP connection with StartTLS before binding, failing if the server refuses the upgrade. Default is `false`.",
+			},
 			attributeNameTlsInsecure: {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "Don't verify the server TLS certificate. Default is `false`.",
 			},
+			attributeNameTlsCACertificate: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{attributeNameTlsCACertificateFile},
+				Description:   "PEM encoded CA certificate bundle used instead of the system certificate store to verify the server certificate.",
+			},
+			attributeNameTlsCACertificateFile: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{attributeNameTlsCACertificate},
+				Description:   "Path to a PEM encoded CA certificate bundle used instead of the system certificate store to verify the server certificate.",
+			},
+			attributeNameTlsClientCertificate: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{attributeNameTlsClientCertificateFile},
+				Description:   "PEM encoded client certificate for mutual TLS, requires `" + attributeNameTlsClientKey + "` or `" + attributeNameTlsClientKeyFile + "`.",
+			},
+			attributeNameTlsClientCertificateFile: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{attributeNameTlsClientCertificate},
+				Description:   "Path to a PEM encoded client certificate for mutual TLS, requires `" + attributeNameTlsClientKey + "` or `" + attributeNameTlsClientKeyFile + "`.",
+			},
+			attributeNameTlsClientKey: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{attributeNameTlsClientKeyFile},
+				Description:   "PEM encoded private key of the client certificate.",
+			},
+			attributeNameTlsClientKeyFile: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{attributeNameTlsClientKey},
+				Description:   "Path to the PEM encoded private key of the client certificate.",
+			},
+			attributeNameTlsServerName: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Server name used for SNI and for verifying the server certificate. Defaults to the LDAP host.",
+			},
+			attributeNameTlsMinVersion: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(tlsVersionNames(), false)),
+				Description:      "Minimum TLS version, one of `1.0`, `1.1`, `1.2` or `1.3`. Defaults to the Go default (`1.2`).",
+			},
+			attributeNameMaxConnections: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          10,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Maximum number of connections used in parallel, if `" + attributeNameWriteUrl + "` is set this applies to reads and writes separately. Connections are opened on demand. Default is `10` (Terraform's default parallelism).",
+			},
+			attributeNameDialTimeout: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  "Duration (e.g. `10s`) after which establishing a connection to a server is given up. Default is `60s`.",
+			},
+			attributeNameRequestTimeout: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  "Duration (e.g. `30s`) after which waiting for the response to a request is given up. Default is no timeout.",
+			},
+			attributeNameSearchTimeLimit: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+				Description:      "Time limit in seconds the server is requested to observe for searches. Default is `0` (no limit requested).",
+			},
+			attributeNameSearchSizeLimit: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+				Description:      "Maximum number of entries the server is requested to return for searches. Default is `0` (no limit requested).",
+			},
+			attributeNameMaxRetries: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          3,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+				Description:      "How often an operation is retried after the connection to the server was lost and re-established. Only searches, replaces, adds and deletes are retried. Default is `3`.",
+			},
+			attributeNameRetryBackoff: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1s",
+				ValidateFunc: validateDuration,
+				Description:  "Duration (e.g. `500ms`, `2s`) to wait before the first retry, the wait is doubled for each further retry. Default is `1s`.",
+			},
+			attributeNameProxiedAuthz: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Authorization identity (`dn:<DN>`, `u:<user>` or a DN) for the proxied authorization control (RFC 4370) sent with every operation, so that ACLs and audit logs apply to this identity instead of `" + attributeNameBindUser + "`. Can be overridden by resources.",
+			},
+			attributeNameProtectedDns: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "DNs which must not be deleted, neither themselves nor any entry below them",
+			},
+			attributeNameDeletedContainer: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "DN of the container resources with `" + attributeNameOnDestroy + " = \"" + onDestroyMove + "\"` are moved to when destroyed",
+			},
 			attributeEntryAttributeNamesCaseSensitive: {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     true,
-				Description: "if the entry attribute names should be handeled case sensitive (for state handling in resource ldap_entry)",
+				Description: "if the entry attribute names should be handeled case sensitive (for state handling in resource ldap_entry), only used if the schema of the server is not available",
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"ldap_entry": resourceLDAPEntry(),
+			"ldap_entry":        resourceLDAPEntry(),
+			"ldap_group":        resourceLDAPGroup(),
+			"ldap_group_member": resourceLDAPGroupMember(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"ldap_entry":   dataSourceLDAPEntry(),
@@ -77,23 +327,80 @@ func Provider() *schema.Provider {
 	}
 }
 
-var EntryAttributeNamesCaseSensitive bool
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
 
+func tlsVersionNames() (names []string) {
+	for name := range tlsVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateDuration(value interface{}, k string) (ws []string, errs []error) {
+	_, err := time.ParseDuration(value.(string))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s: %s", k, err))
+	}
+	return nil, errs
+}
+
+// providerConfigure doesn't connect, the connection is established lazily by the first LDAP operation.
+// Thus the provider can be configured with values which are unknown during plan.
 func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	EntryAttributeNamesCaseSensitive = d.Get(attributeEntryAttributeNamesCaseSensitive).(bool)
 	client := &client2.Client{
-		Host:         d.Get(attributeNameHost).(string),
-		Port:         d.Get(attributeNamePort).(int),
-		BindUser:     d.Get(attributeNameBindUser).(string),
-		BindPassword: d.Get(attributeNameBindPassword).(string),
-		TLS:          d.Get(attributeNameTls).(bool),
-		TLSInsecure:  d.Get(attributeNameTlsInsecure).(bool),
+		URLs:                     *getAttributeListFromAttribute(d, attributeNameUrls),
+		RandomizeURLs:            d.Get(attributeNameRandomizeUrls).(bool),
+		WriteURL:                 d.Get(attributeNameWriteUrl).(string),
+		Host:                     d.Get(attributeNameHost).(string),
+		Port:                     d.Get(attributeNamePort).(int),
+		BindMethod:               d.Get(attributeNameBindMethod).(string),
+		BindUser:                 d.Get(attributeNameBindUser).(string),
+		BindPassword:             d.Get(attributeNameBindPassword).(string),
+		BindPasswordFile:         d.Get(attributeNameBindPasswordFile).(string),
+		BindPasswordCommand:      *getAttributeListFromAttribute(d, attributeNameBindPasswordCommand),
+		NTLMDomain:               d.Get(attributeNameNtlmDomain).(string),
+		NTLMHash:                 d.Get(attributeNameNtlmHash).(string),
+		KerberosRealm:            d.Get(attributeNameKerberosRealm).(string),
+		KerberosKeytab:           d.Get(attributeNameKerberosKeytab).(string),
+		KerberosCCache:           d.Get(attributeNameKerberosCCache).(string),
+		KerberosConfig:           d.Get(attributeNameKerberosConfig).(string),
+		KerberosSPN:              d.Get(attributeNameKerberosSpn).(string),
+		TLS:                      d.Get(attributeNameTls).(bool),
+		StartTLS:                 d.Get(attributeNameStartTls).(bool),
+		TLSInsecure:              d.Get(attributeNameTlsInsecure).(bool),
+		TLSCACertificate:         d.Get(attributeNameTlsCACertificate).(string),
+		TLSCACertificateFile:     d.Get(attributeNameTlsCACertificateFile).(string),
+		TLSClientCertificate:     d.Get(attributeNameTlsClientCertificate).(string),
+		TLSClientCertificateFile: d.Get(attributeNameTlsClientCertificateFile).(string),
+		TLSClientKey:             d.Get(attributeNameTlsClientKey).(string),
+		TLSClientKeyFile:         d.Get(attributeNameTlsClientKeyFile).(string),
+		TLSServerName:            d.Get(attributeNameTlsServerName).(string),
+		TLSMinVersion:            tlsVersions[d.Get(attributeNameTlsMinVersion).(string)],
+		MaxRetries:               d.Get(attributeNameMaxRetries).(int),
+		MaxConnections:           d.Get(attributeNameMaxConnections).(int),
+		SearchTimeLimit:          d.Get(attributeNameSearchTimeLimit).(int),
+		SearchSizeLimit:          d.Get(attributeNameSearchSizeLimit).(int),
+		ProxiedAuthz:             d.Get(attributeNameProxiedAuthz).(string),
+		ProtectedDNs:             *getAttributeListFromAttribute(d, attributeNameProtectedDns),
+		DeletedContainer:         d.Get(attributeNameDeletedContainer).(string),
+
+		EntryAttributeNamesCaseSensitive: d.Get(attributeEntryAttributeNamesCaseSensitive).(bool),
 	}
 
-	err := client.Connect()
-	if err != nil {
-		return nil, diag.FromErr(err)
+	if url := d.Get(attributeNameUrl).(string); url != "" {
+		client.URLs = []string{url}
 	}
 
+	// durations are validated by the schema, unset or unknown values result in 0
+	client.RetryBackoff, _ = time.ParseDuration(d.Get(attributeNameRetryBackoff).(string))
+	client.DialTimeout, _ = time.ParseDuration(d.Get(attributeNameDialTimeout).(string))
+	client.RequestTimeout, _ = time.ParseDuration(d.Get(attributeNameRequestTimeout).(string))
+
 	return client, nil
 }
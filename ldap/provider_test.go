@@ -2,7 +2,9 @@ package ldap
 
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/l-with/terraform-provider-ldap/client"
 	"os"
+	"strconv"
 	"testing"
 )
 
@@ -27,3 +29,17 @@ func testEnvIsSet(k string, t *testing.T) {
 		t.Fatalf("%[1]s must be set for acceptance tests", k)
 	}
 }
+
+// testAccClient returns a client for changing entries outside of terraform during acceptance testing.
+func testAccClient(t *testing.T) *client.Client {
+	port, err := strconv.Atoi(os.Getenv(ldapPortEnvVarName))
+	if err != nil {
+		t.Fatalf("%s: %s", ldapPortEnvVarName, err)
+	}
+	return &client.Client{
+		Host:         os.Getenv(ldapHostEnvVarName),
+		Port:         port,
+		BindUser:     os.Getenv(ldapBindUserEnvVarName),
+		BindPassword: os.Getenv(ldapBindPasswordEnvVarName),
+	}
+}
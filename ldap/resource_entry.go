@@ -3,14 +3,16 @@ package ldap
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
+	"reflect"
 
 	"github.com/go-ldap/ldap/v3"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/l-with/terraform-provider-ldap/client"
 )
 
@@ -25,17 +27,21 @@ func resourceLDAPEntry() *schema.Resource {
 			StateContext: resourceLDAPEntryImport,
 		},
 
+		CustomizeDiff: resourceLDAPEntryCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			attributeNameDn: {
-				Description: "DN of the LDAP entry",
+				Description: "DN of the LDAP entry, changing it renames or moves the entry (ModifyDN), the entry is only recreated if the server refuses that",
 				Type:        schema.TypeString,
-				ForceNew:    true,
 				Required:    true,
 			},
 			attributeNameDataJson: {
-				Description: "JSON-encoded string with the values of the attributes of the entry (s. https://pkg.go.dev/github.com/go-ldap/ldap/v3#EntryAttribute)",
-				Type:        schema.TypeString,
-				Required:    true,
+				Description:  "JSON-encoded string with the values of the attributes of the entry (s. https://pkg.go.dev/github.com/go-ldap/ldap/v3#EntryAttribute), alternative to `" + attributeNameAttributes + "`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{attributeNameDataJson, attributeNameAttributes},
+				// attribute names are compared by the schema of the server in resourceLDAPEntryCustomizeDiff
 				DiffSuppressFunc: func(k, oldValue, newValue string, d *schema.ResourceData) bool {
 					if d.Id() == "" {
 						return false
@@ -45,51 +51,6 @@ func resourceLDAPEntry() *schema.Resource {
 					var newLdapEntry client.LdapEntry
 					json.Unmarshal([]byte(newValue), &newLdapEntry.Entry)
 
-					attributeNamesCaseSensitive := EntryAttributeNamesCaseSensitive
-					if !attributeNamesCaseSensitive {
-						caseSensitiveAttributeList := new([]string)
-						for _, caseSensitiveAttributeListValue := range d.Get(attributeNameCaseSensitiveAttibuteNames).([]interface{}) {
-							*caseSensitiveAttributeList = append(*caseSensitiveAttributeList, caseSensitiveAttributeListValue.(string))
-						}
-						{
-							toLower := true
-							for attributeName := range oldLdapEntry.Entry {
-								for _, caseSensitiveAttribute := range *caseSensitiveAttributeList {
-									if strings.EqualFold(attributeName, caseSensitiveAttribute) {
-										toLower = false
-										break
-									}
-								}
-								if !toLower {
-									continue
-								}
-								if attributeName == strings.ToLower(attributeName) {
-									continue
-								}
-								oldLdapEntry.Entry[strings.ToLower(attributeName)] = oldLdapEntry.Entry[attributeName]
-								delete(oldLdapEntry.Entry, attributeName)
-							}
-						}
-						{
-							toLower := true
-							for attributeName := range newLdapEntry.Entry {
-								for _, caseSensitiveAttribute := range *caseSensitiveAttributeList {
-									if strings.EqualFold(attributeName, caseSensitiveAttribute) {
-										toLower = false
-										continue
-									}
-								}
-								if !toLower {
-									continue
-								}
-								if attributeName == strings.ToLower(attributeName) {
-									continue
-								}
-								newLdapEntry.Entry[strings.ToLower(attributeName)] = newLdapEntry.Entry[attributeName]
-								delete(newLdapEntry.Entry, attributeName)
-							}
-						}
-					}
 					client.SortLdapEntryValues(&oldLdapEntry)
 					client.SortLdapEntryValues(&newLdapEntry)
 					oldJsonData, _ := json.Marshal(oldLdapEntry.Entry)
@@ -107,6 +68,15 @@ func resourceLDAPEntry() *schema.Resource {
 					return nil, errs
 				},
 			},
+			attributeNameAttributes: {
+				Description:  "the attributes of the entry with their values, alternative to `" + attributeNameDataJson + "`",
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Computed:     true,
+				ConfigMode:   schema.SchemaConfigModeAttr,
+				ExactlyOneOf: []string{attributeNameDataJson, attributeNameAttributes},
+				Elem:         attributesElem(),
+			},
 			attributeNameIgnoreAttributes: {
 				Description: "list of attributes to ignore",
 				Type:        schema.TypeList,
@@ -131,6 +101,12 @@ func resourceLDAPEntry() *schema.Resource {
 				Optional:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
+			attributeNameNoBase64EncodeAttributes: {
+				Description: "list of attributes which are not base64 encoded, although they are binary according to the schema of the LDAP server",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 			attributeNameCaseSensitiveAttibuteNames: {
 				Description: "list of attributes with case-sensitive names",
 				Type:        schema.TypeList,
@@ -155,16 +131,221 @@ func resourceLDAPEntry() *schema.Resource {
 					return nil, errs
 				},
 			},
+			attributeNameEntryUUID: {
+				Description: "stable identifier of the LDAP entry (`entryUUID`, `nsUniqueId` or `objectGUID`), used to find the entry if it was renamed or moved outside of terraform",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			attributeNameDeleteSubtree: {
+				Description: "if the entries below the LDAP entry, e.g. created outside of terraform, are deleted with it. Defaults to `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			attributeNameDeletionProtection: {
+				Description: "if destroying the LDAP entry is refused. Defaults to `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			attributeNameOnDestroy: {
+				Description:      "what happens to the LDAP entry when it is destroyed, `" + onDestroyDelete + "` or `" + onDestroyMove + "` (to `" + attributeNameDeletedContainer + "` with ModifyDN, so that it can be restored, the time of the move is appended to the RDN value if the container already has an entry with the same RDN). Defaults to `" + onDestroyDelete + "`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          onDestroyDelete,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{onDestroyDelete, onDestroyMove}, false)),
+			},
+			attributeNameDeletedContainer: {
+				Description: "DN of the container the LDAP entry is moved to with `" + attributeNameOnDestroy + " = \"" + onDestroyMove + "\"`, overrides `" + attributeNameDeletedContainer + "` of the provider",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			attributeNameModifyStrategy: {
+				Description:      "how changed attributes are modified, `" + client.ModifyStrategyReplace + "` (all values are replaced) or `" + client.ModifyStrategyValues + "` (only added and removed values are sent, attributes with a single value are still replaced). Defaults to `" + client.ModifyStrategyReplace + "`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          client.ModifyStrategyReplace,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{client.ModifyStrategyReplace, client.ModifyStrategyValues}, false)),
+			},
+			attributeNameAssertUnchanged: {
+				Description: "if modifications, renames and deletions of the LDAP entry are only performed if the entry was not changed since it was read (its `" + attributeNameRevision + "` is unchanged), using the assertion control (RFC 4528). Defaults to `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			attributeNameRevision: {
+				Description: "revision of the LDAP entry when it was read (`entryCSN`, `uSNChanged` or `modifyTimestamp`)",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			attributeNameValidateSchema: {
+				Description: "if the entry is validated against the schema of the LDAP server (object classes, required and allowed attributes, single-valued attributes) when planning. Defaults to `false`. " +
+					"The validation is skipped if `" + attributeNameRestrictAttributes + "`, `" + attributeNameIgnoreAttributes + "` or `" + attributeNameIgnoreAttributePatterns + "` are used, because the entry is not managed completely then. " +
+					"It should not be enabled for servers which provide required attributes themselves, e.g. `objectCategory` in Active Directory.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			attributeNameProxiedAuthz: {
+				Description: "authorization identity (`dn:<DN>`, `u:<user>` or a DN) for the proxied authorization control (RFC 4370), overrides `proxied_authz` of the provider",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
 		},
 	}
 }
 
+// resourceLDAPEntryCustomizeDiff suppresses changes of the values which match each other according to
+// the schema of the server, marks the revision as unknown if the entry is going to be changed and
+// validates the entry against the schema of the server.
+func resourceLDAPEntryCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	cl := m.(*client.Client)
+
+	if d.Id() != "" {
+		err := suppressSchemaEqualChanges(ctx, d, cl)
+		if err != nil {
+			return err
+		}
+		if d.HasChanges(attributeNameDn, attributeNameDataJson, attributeNameAttributes) {
+			err = d.SetNewComputed(attributeNameRevision)
+			if err != nil {
+				return err
+			}
+		}
+		// the representation of the entry which is not configured is read again after the change
+		key := configuredEntryKey(d)
+		if d.HasChange(key) {
+			otherKey := attributeNameAttributes
+			if key == attributeNameAttributes {
+				otherKey = attributeNameDataJson
+			}
+			err = d.SetNewComputed(otherKey)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.Get(attributeNameValidateSchema).(bool) && (d.Id() == "" || d.HasChanges(attributeNameDn, attributeNameDataJson, attributeNameAttributes)) {
+		return validateEntrySchema(ctx, d, cl)
+	}
+	return nil
+}
+
+func suppressSchemaEqualChanges(ctx context.Context, d *schema.ResourceDiff, cl *client.Client) error {
+	for _, key := range []string{attributeNameDataJson, attributeNameAttributes} {
+		if !d.HasChange(key) || !d.NewValueKnown(key) {
+			continue
+		}
+		oldValue, newValue := d.GetChange(key)
+		var oldEntry, newEntry map[string][]string
+		if key == attributeNameAttributes {
+			oldEntry, newEntry = expandAttributes(oldValue), expandAttributes(newValue)
+		} else if json.Unmarshal([]byte(oldValue.(string)), &oldEntry) != nil || json.Unmarshal([]byte(newValue.(string)), &newEntry) != nil {
+			continue
+		}
+		if entriesEqual(ctx, d, cl, oldEntry, newEntry) {
+			err := d.Clear(key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// entriesEqual reports whether the attributes of entry and otherEntry match each other according to the
+// schema of the server. Without the schema the names of the attributes are compared ignoring case,
+// if entry_attribute_names_case_sensitive is false and they are not in case_sensitive_attribute_names.
+func entriesEqual(ctx context.Context, d *schema.ResourceDiff, cl *client.Client, entry map[string][]string, otherEntry map[string][]string) bool {
+	ldapSchema, err := cl.Schema(ctx)
+	if err == nil {
+		return ldapSchema.EntriesEqual(entry, otherEntry)
+	}
+	tflog.Warn(ctx, "comparing values without the schema of the server", map[string]interface{}{"error": err.Error()})
+
+	if !cl.EntryAttributeNamesCaseSensitive {
+		var caseSensitiveAttributeNames []string
+		for _, value := range d.Get(attributeNameCaseSensitiveAttibuteNames).([]interface{}) {
+			caseSensitiveAttributeNames = append(caseSensitiveAttributeNames, value.(string))
+		}
+		entry = lowerAttributeNames(entry, caseSensitiveAttributeNames)
+		otherEntry = lowerAttributeNames(otherEntry, caseSensitiveAttributeNames)
+	}
+	ldapEntry := client.LdapEntry{Entry: entry}
+	otherLdapEntry := client.LdapEntry{Entry: otherEntry}
+	client.SortLdapEntryValues(&ldapEntry)
+	client.SortLdapEntryValues(&otherLdapEntry)
+	return reflect.DeepEqual(ldapEntry.Entry, otherLdapEntry.Entry)
+}
+
+// configuredEntryKey returns the key of the representation of the entry which is configured,
+// data_json or attributes.
+func configuredEntryKey(d *schema.ResourceDiff) string {
+	if rawConfig := d.GetRawConfig(); rawConfig.IsKnown() && !rawConfig.IsNull() && !rawConfig.GetAttr(attributeNameAttributes).IsNull() {
+		return attributeNameAttributes
+	}
+	return attributeNameDataJson
+}
+
+// validateEntrySchema validates the planned entry against the schema of the server.
+// The validation is skipped if the schema cannot be read, the entry is not known yet
+// or only a part of the entry is managed by the resource.
+func validateEntrySchema(ctx context.Context, d *schema.ResourceDiff, cl *client.Client) error {
+	for _, key := range []string{attributeNameRestrictAttributes, attributeNameIgnoreAttributes, attributeNameIgnoreAttributePatterns} {
+		if len(d.Get(key).([]interface{})) > 0 {
+			tflog.Debug(ctx, "skipping the schema validation of the partly managed entry", map[string]interface{}{"attribute": key})
+			return nil
+		}
+	}
+
+	key := configuredEntryKey(d)
+	if !d.NewValueKnown(key) || !d.NewValueKnown(attributeNameDn) || !d.NewValueKnown(attributeNameDataJsonCreateDefaults) {
+		tflog.Debug(ctx, "skipping the schema validation of the entry with unknown values")
+		return nil
+	}
+
+	ldapSchema, err := cl.Schema(ctx)
+	if err != nil {
+		tflog.Warn(ctx, "skipping the schema validation of the entry", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+
+	ldapEntry := client.LdapEntry{Dn: d.Get(attributeNameDn).(string)}
+	if key == attributeNameAttributes {
+		ldapEntry.Entry = expandAttributes(d.Get(attributeNameAttributes))
+	} else if err := json.Unmarshal([]byte(d.Get(attributeNameDataJson).(string)), &ldapEntry.Entry); err != nil {
+		return nil
+	}
+
+	// attributes which are not managed by the resource are assumed to be present
+	createDefaults := parseCreateDefaults(d.Get(attributeNameDataJsonCreateDefaults))
+	if d.Id() == "" {
+		for attributeName, values := range createDefaults {
+			if _, present := ldapEntry.Entry[attributeName]; !present {
+				ldapEntry.Entry[attributeName] = values
+			}
+		}
+	}
+	assumePresent := func(attributeName string) bool {
+		_, present := createDefaults[attributeName]
+		return present && d.Id() != ""
+	}
+
+	errs := ldapSchema.ValidateEntry(&ldapEntry, assumePresent)
+	if len(errs) > 0 {
+		return fmt.Errorf("the entry '%s' does not match the schema of the server: %w", ldapEntry.Dn, errors.Join(errs...))
+	}
+	return nil
+}
+
 func resourceLDAPEntryImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
 	return []*schema.ResourceData{d}, nil
 }
 
 func resourceLDAPEntryRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cl := m.(*client.Client)
+	ctx = client.ContextWithProxiedAuthz(ctx, d.Get(attributeNameProxiedAuthz).(string))
 
 	id := d.Id()
 
@@ -176,17 +357,51 @@ func resourceLDAPEntryRead(ctx context.Context, d *schema.ResourceData, m interf
 		restrictAttributes = getAttributeListFromAttribute(d, attributeNameRestrictAttributes)
 	}
 
-	ldapEntry, err := cl.ReadEntryByDN(id, "("+dummyFilter+")", restrictAttributes)
-	if err != nil {
-		if err.(*ldap.Error).ResultCode == ldap.LDAPResultNoSuchObject {
+	// the attributes holding the stable identifier and the revision are requested in addition and removed from the entry again
+	attributes := append([]string{}, *restrictAttributes...)
+	var additionalAttributes []string
+	for _, additionalAttributeName := range append(append([]string{}, client.UUIDAttributeNames...), client.RevisionAttributeNames...) {
+		if !containsFold(attributes, additionalAttributeName) {
+			attributes = append(attributes, additionalAttributeName)
+			additionalAttributes = append(additionalAttributes, additionalAttributeName)
+		}
+	}
+
+	ldapEntry, err := cl.ReadEntryByDN(ctx, id, "("+dummyFilter+")", &attributes)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		entryUUID := d.Get(attributeNameEntryUUID).(string)
+		if entryUUID == "" {
+			d.SetId("")
+			return nil
+		}
+		ldapEntry, err = cl.ReadEntryByUUID(ctx, entryUUID, &attributes)
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
 			d.SetId("")
 			return nil
 		}
+		if err == nil {
+			tflog.Info(ctx, "entry was renamed or moved", map[string]interface{}{"dn": id, "new_dn": ldapEntry.Dn})
+			d.SetId(ldapEntry.Dn)
+			id = ldapEntry.Dn
+		}
+	}
+	if err != nil {
 		return diag.FromErr(err)
 	}
+	d.Set(attributeNameEntryUUID, client.GetEntryUUID(ldapEntry))
+	d.Set(attributeNameRevision, client.GetEntryRevision(ldapEntry))
+	for attributeName := range ldapEntry.Entry {
+		if containsFold(additionalAttributes, attributeName) {
+			delete(ldapEntry.Entry, attributeName)
+		}
+	}
+
 	dn := id
 	d.Set(attributeNameDn, dn)
 	ignoreAndBase64Encode := getIgnoreAndBase64encode(d)
+	if err := base64encodeBinaryAttributes(ctx, cl, ignoreAndBase64Encode); err != nil {
+		return diag.FromErr(err)
+	}
 	appendCreateDefaultKeysToIgnore(ignoreAndBase64Encode, d)
 	ignoreRDNAttributes := client.GetRDNAttributes(ldapEntry, dn)
 	if ignoreRDNAttributes != nil {
@@ -194,6 +409,16 @@ func resourceLDAPEntryRead(ctx context.Context, d *schema.ResourceData, m interf
 	}
 	client.IgnoreAndBase64encodeAttributes(ldapEntry, ignoreAndBase64Encode)
 
+	// the attributes keep the names used in the configuration, e.g. aliases or other cases
+	names := attributesNames(d.Get(attributeNameAttributes))
+	var priorEntry map[string][]string
+	if json.Unmarshal([]byte(d.Get(attributeNameDataJson).(string)), &priorEntry) == nil {
+		for name := range priorEntry {
+			names = append(names, name)
+		}
+	}
+	ldapEntry.Entry = renameAttributes(ldapEntry.Entry, names, sameAttributeFunc(ctx, d, cl))
+
 	jsonData, err := json.Marshal(ldapEntry.Entry)
 	if err != nil {
 		return diag.Errorf("error marshaling JSON for %q: %s", dn, err)
@@ -204,24 +429,35 @@ func resourceLDAPEntryRead(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
+	err = d.Set(attributeNameAttributes, flattenAttributes(ldapEntry.Entry))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	return diag.FromErr(err)
 }
 
 func resourceLDAPEntryCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cl := m.(*client.Client)
+	ctx = client.ContextWithProxiedAuthz(ctx, d.Get(attributeNameProxiedAuthz).(string))
 
 	dn := d.Get(attributeNameDn).(string)
 
-	dataJson := d.Get(attributeNameDataJson)
-
 	var ldapEntry client.LdapEntry
 
 	ignoreAndBase64Encode := getIgnoreAndBase64encode(d)
-	err := json.Unmarshal([]byte(dataJson.(string)), &ldapEntry.Entry)
+	if err := base64encodeBinaryAttributes(ctx, cl, ignoreAndBase64Encode); err != nil {
+		return diag.FromErr(err)
+	}
+	var err error
+	ldapEntry.Entry, err = getEntry(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = client.IgnoreAndBase64decodeAttributes(&ldapEntry, ignoreAndBase64Encode)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	client.IgnoreAndBase64decodeAttributes(&ldapEntry, ignoreAndBase64Encode)
 	for key, values := range getCreateDefaults(d) {
 		if _, present := ldapEntry.Entry[key]; !present {
 			ldapEntry.Entry[key] = values
@@ -229,7 +465,7 @@ func resourceLDAPEntryCreate(ctx context.Context, d *schema.ResourceData, m inte
 	}
 	ldapEntry.Dn = dn
 
-	err = cl.CreateEntry(&ldapEntry)
+	err = cl.CreateEntry(ctx, &ldapEntry)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -241,23 +477,53 @@ func resourceLDAPEntryCreate(ctx context.Context, d *schema.ResourceData, m inte
 
 func resourceLDAPEntryUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cl := m.(*client.Client)
+	ctx = client.ContextWithProxiedAuthz(ctx, d.Get(attributeNameProxiedAuthz).(string))
 
 	dn := d.Get(attributeNameDn).(string)
 
+	if d.Get(attributeNameAssertUnchanged).(bool) {
+		revision, _ := d.GetChange(attributeNameRevision)
+		ctx = client.ContextWithAssertion(ctx, d.Id(), assertionFilter(revision.(string)))
+	}
+
+	if d.HasChange(attributeNameDn) {
+		oldDn, _ := d.GetChange(attributeNameDn)
+		err := cl.RenameEntry(ctx, oldDn.(string), dn)
+		if ldap.IsErrorAnyOf(err, ldap.LDAPResultUnwillingToPerform, ldap.LDAPResultAffectsMultipleDSAs) {
+			tflog.Warn(ctx, "server refused to rename entry, recreating it", map[string]interface{}{"dn": oldDn, "new_dn": dn, "error": err.Error()})
+			return resourceLDAPEntryRecreate(ctx, d, m, oldDn.(string))
+		}
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.SetId(dn)
+
+		if d.Get(attributeNameAssertUnchanged).(bool) {
+			// the rename changed the revision, the modifications assert the revision of the renamed entry
+			revisionAttributeNames := append([]string{}, client.RevisionAttributeNames...)
+			renamedEntry, err := cl.ReadEntryByDN(ctx, dn, "(objectClass=*)", &revisionAttributeNames)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			ctx = client.ContextWithAssertion(ctx, dn, assertionFilter(client.GetEntryRevision(renamedEntry)))
+		}
+	}
+
 	newIgnoreAndBase64Encode := getIgnoreAndBase64encode(d)
+	if err := base64encodeBinaryAttributes(ctx, cl, newIgnoreAndBase64Encode); err != nil {
+		return diag.FromErr(err)
+	}
 	appendCreateDefaultKeysToIgnore(newIgnoreAndBase64Encode, d)
 	oldIgnoreAndBas64Encode := getOldIgnoreAndBase64encode(d)
+	if err := base64encodeBinaryAttributes(ctx, cl, oldIgnoreAndBas64Encode); err != nil {
+		return diag.FromErr(err)
+	}
 	appendOldCreateDefaultKeysToIgnore(oldIgnoreAndBas64Encode, d)
 	var err error
-	if d.HasChanges(attributeNameDataJson) {
-		oldDataJson, newDataJson := d.GetChange(attributeNameDataJson)
+	if d.HasChanges(attributeNameDataJson, attributeNameAttributes) {
 		var ldapEntryOld client.LdapEntry
 		var ldapEntryNew client.LdapEntry
-		err = json.Unmarshal([]byte(oldDataJson.(string)), &ldapEntryOld.Entry)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		err = json.Unmarshal([]byte(newDataJson.(string)), &ldapEntryNew.Entry)
+		ldapEntryOld.Entry, ldapEntryNew.Entry, err = getEntryChange(d)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -272,6 +538,15 @@ func resourceLDAPEntryUpdate(ctx context.Context, d *schema.ResourceData, m inte
 		ldapEntryOld.Dn = dn
 		ldapEntryNew.Dn = dn
 
+		ldapSchema, err := cl.Schema(ctx)
+		if err != nil {
+			tflog.Warn(ctx, "comparing values without the schema of the server", map[string]interface{}{"error": err.Error()})
+		} else {
+			// renaming an attribute, e.g. to an alias, doesn't change it
+			ldapEntryOld.Entry = ldapSchema.CanonicalizeAttributeNames(ldapEntryOld.Entry)
+			ldapEntryNew.Entry = ldapSchema.CanonicalizeAttributeNames(ldapEntryNew.Entry)
+		}
+
 		var oldAttributeNames []interface{}
 		for oldAttributeName := range ldapEntryOld.Entry {
 			oldAttributeNames = append(oldAttributeNames, oldAttributeName)
@@ -289,6 +564,12 @@ func resourceLDAPEntryUpdate(ctx context.Context, d *schema.ResourceData, m inte
 
 		changedAttributeNameSet := schema.NewSet(schema.HashString, []interface{}{})
 		for _, attributeName := range commonAttributeNameSet.List() {
+			if ldapSchema != nil {
+				if !ldapSchema.ValuesEqual(attributeName.(string), ldapEntryOld.Entry[attributeName.(string)], ldapEntryNew.Entry[attributeName.(string)]) {
+					changedAttributeNameSet.Add(attributeName)
+				}
+				continue
+			}
 			oldValuesJson, err := json.Marshal(ldapEntryOld.Entry[attributeName.(string)])
 			if err != nil {
 				return diag.FromErr(err)
@@ -301,7 +582,7 @@ func resourceLDAPEntryUpdate(ctx context.Context, d *schema.ResourceData, m inte
 				changedAttributeNameSet.Add(attributeName)
 			}
 		}
-		err = cl.UpdateEntry(&ldapEntryNew, deletedAttributeNameSet, addedAttributeNameSet, changedAttributeNameSet)
+		err = cl.UpdateEntry(ctx, &ldapEntryOld, &ldapEntryNew, deletedAttributeNameSet, addedAttributeNameSet, changedAttributeNameSet, d.Get(attributeNameModifyStrategy).(string))
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -309,16 +590,63 @@ func resourceLDAPEntryUpdate(ctx context.Context, d *schema.ResourceData, m inte
 	return resourceLDAPEntryRead(ctx, d, m)
 }
 
+// resourceLDAPEntryRecreate creates the entry with its new DN and deletes the entry with the old DN
+// like destroying the resource would, it is refused if the entry is protected from deletion.
+func resourceLDAPEntryRecreate(ctx context.Context, d *schema.ResourceData, m interface{}, oldDn string) diag.Diagnostics {
+	cl := m.(*client.Client)
+
+	if d.Get(attributeNameDeletionProtection).(bool) {
+		return diag.Errorf("the server refused to rename the entry '%s' to '%s' and recreating it is refused because it is protected from deletion by %s", oldDn, d.Get(attributeNameDn).(string), attributeNameDeletionProtection)
+	}
+
+	diags := resourceLDAPEntryCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+
+	err := deleteLDAPEntry(ctx, d, cl, oldDn)
+	if err != nil {
+		return append(diags, diag.Errorf("entry '%s' was recreated, but deleting the old entry '%s' failed: %s", d.Id(), oldDn, err)...)
+	}
+
+	return diags
+}
+
 func resourceLDAPEntryDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	tflog.Info(ctx, "Delete")
 	cl := m.(*client.Client)
+	ctx = client.ContextWithProxiedAuthz(ctx, d.Get(attributeNameProxiedAuthz).(string))
 
 	dn := d.Get(attributeNameDn).(string)
 
-	err := cl.DeleteEntry(dn)
+	if d.Get(attributeNameDeletionProtection).(bool) {
+		return diag.Errorf("the entry '%s' is protected from deletion by %s", dn, attributeNameDeletionProtection)
+	}
+
+	if d.Get(attributeNameAssertUnchanged).(bool) {
+		ctx = client.ContextWithAssertion(ctx, dn, assertionFilter(d.Get(attributeNameRevision).(string)))
+	}
+
+	err := deleteLDAPEntry(ctx, d, cl, dn)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	return nil
 }
+
+// deleteLDAPEntry deletes the entry dn, moves it to the deleted container or deletes it with its subtree
+// according to on_destroy and delete_subtree.
+func deleteLDAPEntry(ctx context.Context, d *schema.ResourceData, cl *client.Client, dn string) error {
+	if d.Get(attributeNameOnDestroy).(string) == onDestroyMove {
+		newDn, err := cl.MoveEntry(ctx, dn, d.Get(attributeNameDeletedContainer).(string))
+		if err == nil {
+			tflog.Info(ctx, "moved entry instead of deleting it", map[string]interface{}{"dn": dn, "new_dn": newDn})
+		}
+		return err
+	}
+	if d.Get(attributeNameDeleteSubtree).(bool) {
+		return cl.DeleteSubtree(ctx, dn)
+	}
+	return cl.DeleteEntry(ctx, dn)
+}
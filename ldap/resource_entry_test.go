@@ -1,13 +1,16 @@
 package ldap
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
 	"testing"
 
+	"github.com/go-ldap/ldap/v3"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/l-with/terraform-provider-ldap/client"
 )
 
@@ -353,3 +356,543 @@ resource "ldap_entry" "user_bobmit" {
 }
 `)
 }
+
+func TestAccResourceLdapEntryRename(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryRename("uid=rename01"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_entry.user_rename", "id", "uid=rename01,ou=users,dc=example,dc=com"),
+					resource.TestCheckResourceAttrSet("ldap_entry.user_rename", "entry_uuid"),
+				),
+			},
+			{
+				Config: testAccResourceEntryRename("uid=rename02"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_entry.user_rename", "id", "uid=rename02,ou=users,dc=example,dc=com"),
+					resource.TestCheckResourceAttr("ldap_entry.user_rename", "dn", "uid=rename02,ou=users,dc=example,dc=com"),
+					resource.TestCheckResourceAttrWith(
+						"data.ldap_entry.user_rename",
+						"data_json",
+						func(value string) error {
+							var e client.LdapEntry
+							if err := json.Unmarshal([]byte(value), &e.Entry); err != nil {
+								return err
+							}
+							if len(e.Entry["uid"]) != 1 || e.Entry["uid"][0] != "rename02" {
+								return fmt.Errorf("uid: expected only the new RDN value 'rename02' after the rename, got %v", e.Entry["uid"])
+							}
+							return nil
+						},
+					),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceEntryRename(rdn string) string {
+	return fmt.Sprintf(`
+resource "ldap_entry" "users_example_com" {
+  dn = "ou=users,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "user_rename" {
+  dn = "%s,${ldap_entry.users_example_com.dn}"
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    ou          = ["users"]
+    givenName   = ["Renate"]
+    sn          = ["Name"]
+    cn          = ["Renate Name"]
+  })
+}
+
+data "ldap_entry" "user_rename" {
+  depends_on = [ldap_entry.user_rename]
+  dn         = ldap_entry.user_rename.dn
+}
+`, rdn)
+}
+
+func TestAccResourceLdapEntryRenameAndModifyAsserted(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryRenameAndModifyAsserted("uid=asserted01", "Renate"),
+				Check:  resource.TestCheckResourceAttrSet("ldap_entry.user_asserted", "revision"),
+			},
+			{
+				// the modifications after the rename assert the revision of the renamed entry
+				Config: testAccResourceEntryRenameAndModifyAsserted("uid=asserted02", "Rena"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_entry.user_asserted", "id", "uid=asserted02,ou=users,dc=example,dc=com"),
+					resource.TestCheckResourceAttrWith(
+						"data.ldap_entry.user_asserted",
+						"data_json",
+						func(value string) error {
+							var e client.LdapEntry
+							if err := json.Unmarshal([]byte(value), &e.Entry); err != nil {
+								return err
+							}
+							if len(e.Entry["givenName"]) != 1 || e.Entry["givenName"][0] != "Rena" {
+								return fmt.Errorf("givenName: expected 'Rena' after the rename, got %v", e.Entry["givenName"])
+							}
+							return nil
+						},
+					),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceEntryRenameAndModifyAsserted(rdn string, givenName string) string {
+	return fmt.Sprintf(`
+resource "ldap_entry" "users_example_com" {
+  dn = "ou=users,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "user_asserted" {
+  dn               = "%s,${ldap_entry.users_example_com.dn}"
+  assert_unchanged = true
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    givenName   = ["%s"]
+    sn          = ["Name"]
+    cn          = ["Renate Name"]
+  })
+}
+
+data "ldap_entry" "user_asserted" {
+  depends_on = [ldap_entry.user_asserted]
+  dn         = ldap_entry.user_asserted.dn
+}
+`, rdn, givenName)
+}
+
+func TestAccResourceLdapEntryRenamedOutside(t *testing.T) {
+	dn := "uid=outside01,ou=users,dc=example,dc=com"
+	renamedDn := "uid=outside02,ou=users,dc=example,dc=com"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryRename("uid=outside01"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_entry.user_rename", "id", dn),
+					resource.TestCheckResourceAttrSet("ldap_entry.user_rename", "entry_uuid"),
+				),
+			},
+			{
+				// the entry renamed outside of terraform is found by its stable identifier and renamed back
+				PreConfig: func() {
+					err := testAccClient(t).RenameEntry(context.Background(), dn, renamedDn)
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccResourceEntryRename("uid=outside01"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccResourceEntryRename("uid=outside01"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_entry.user_rename", "id", dn),
+					resource.TestCheckResourceAttr("ldap_entry.user_rename", "dn", dn),
+					func(*terraform.State) error {
+						_, err := testAccClient(t).ReadEntryByDN(context.Background(), renamedDn, "("+dummyFilter+")", &[]string{"1.1"})
+						if !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+							return fmt.Errorf("expected the entry '%s' to be renamed back, got %v", renamedDn, err)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceLdapEntryModifyStrategyValues(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryModifyStrategyValues(`"cn=member1", "cn=member2"`),
+			},
+			{
+				Config: testAccResourceEntryModifyStrategyValues(`"cn=member2", "cn=member3"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ldap_entry.group_values", "revision"),
+					resource.TestCheckResourceAttrWith(
+						"data.ldap_entry.group_values",
+						"data_json",
+						func(value string) error {
+							var e client.LdapEntry
+							if err := json.Unmarshal([]byte(value), &e.Entry); err != nil {
+								return err
+							}
+							client.SortLdapEntryValues(&e)
+							if fmt.Sprint(e.Entry["member"]) != "[cn=member2 cn=member3]" {
+								return fmt.Errorf("member: expected [cn=member2 cn=member3], got %v", e.Entry["member"])
+							}
+							return nil
+						},
+					),
+				),
+			},
+			{
+				// the values match the values of the entry according to distinguishedNameMatch
+				Config:   testAccResourceEntryModifyStrategyValues(`"CN=Member3", "cn=member2"`),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccResourceEntryModifyStrategyValues(members string) string {
+	return fmt.Sprintf(`
+resource "ldap_entry" "groups_example_com" {
+  dn = "ou=groups,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "group_values" {
+  dn               = "cn=values,${ldap_entry.groups_example_com.dn}"
+  modify_strategy  = "values"
+  assert_unchanged = true
+  data_json = jsonencode({
+    objectClass = ["groupOfNames"]
+    member      = [%s]
+  })
+}
+
+data "ldap_entry" "group_values" {
+  depends_on = [ldap_entry.group_values]
+  dn         = ldap_entry.group_values.dn
+}
+`, members)
+}
+
+func TestAccResourceLdapEntryAttributes(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryAttributes("Street"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("ldap_entry.user_attributes", "attributes.*", map[string]string{
+						"name":     "street",
+						"values.#": "1",
+						"values.0": "Street",
+					}),
+				),
+			},
+			{
+				Config: testAccResourceEntryAttributes("NewStreet"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("data.ldap_entry.user_attributes", "attributes.*", map[string]string{
+						"name":     "street",
+						"values.#": "1",
+						"values.0": "NewStreet",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceEntryAttributes(street string) string {
+	return fmt.Sprintf(`
+resource "ldap_entry" "users_example_com" {
+  dn = "ou=users,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "user_attributes" {
+  dn = "uid=attributes01,${ldap_entry.users_example_com.dn}"
+  attributes = [
+    { name = "objectClass", values = ["inetOrgPerson"] },
+    { name = "givenName", values = ["Attri"] },
+    { name = "sn", values = ["Butes"] },
+    { name = "cn", values = ["Attri Butes"] },
+    { name = "street", values = ["%s"] },
+  ]
+}
+
+data "ldap_entry" "user_attributes" {
+  depends_on = [ldap_entry.user_attributes]
+  dn         = ldap_entry.user_attributes.dn
+}
+`, street)
+}
+
+func TestAccResourceLdapEntryValidateSchema(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccResourceEntryValidateSchema(),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("attribute 'sn' is required by the object classes"),
+			},
+		},
+	})
+}
+
+func testAccResourceEntryValidateSchema() string {
+	return `
+resource "ldap_entry" "user_invalid" {
+  dn              = "uid=invalid01,ou=users,dc=example,dc=com"
+  validate_schema = true
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    cn          = ["In Valid"]
+  })
+}
+`
+}
+
+func TestAccResourceLdapEntryDeleteSubtree(t *testing.T) {
+	dn := "ou=subtree01,dc=example,dc=com"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy: func(*terraform.State) error {
+			_, err := testAccClient(t).ReadEntryByDN(context.Background(), dn, "("+dummyFilter+")", &[]string{"1.1"})
+			if !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+				return fmt.Errorf("expected the entry '%s' to be deleted with its subtree, got %v", dn, err)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryDeleteSubtree(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_entry.subtree", "delete_subtree", "true"),
+					// an entry below the entry is added outside of terraform
+					func(*terraform.State) error {
+						return testAccClient(t).CreateEntry(context.Background(), &client.LdapEntry{
+							Dn: "ou=child01," + dn,
+							Entry: map[string][]string{
+								"objectClass": {"organizationalUnit"},
+								"ou":          {"child01"},
+							},
+						})
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceEntryDeleteSubtree() string {
+	return `
+resource "ldap_entry" "subtree" {
+  dn             = "ou=subtree01,dc=example,dc=com"
+  delete_subtree = true
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+`
+}
+
+func TestAccResourceLdapEntryDeletionProtection(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryDeletionProtection(true, true),
+			},
+			{
+				Config:      testAccResourceEntryDeletionProtection(false, true),
+				ExpectError: regexp.MustCompile("is protected from deletion by deletion_protection"),
+			},
+			{
+				Config: testAccResourceEntryDeletionProtection(true, false),
+				Check:  resource.TestCheckResourceAttr("ldap_entry.user_protected", "deletion_protection", "false"),
+			},
+		},
+	})
+}
+
+func testAccResourceEntryDeletionProtection(withEntry bool, deletionProtection bool) string {
+	config := `
+resource "ldap_entry" "users_example_com" {
+  dn = "ou=users,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+`
+	if withEntry {
+		config += fmt.Sprintf(`
+resource "ldap_entry" "user_protected" {
+  dn                  = "uid=protected01,${ldap_entry.users_example_com.dn}"
+  deletion_protection = %t
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    sn          = ["Protected"]
+    cn          = ["Protected 01"]
+  })
+}
+`, deletionProtection)
+	}
+	return config
+}
+
+func TestAccResourceLdapEntryOnDestroyMove(t *testing.T) {
+	movedDn := "uid=moved01,ou=deleted,dc=example,dc=com"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryOnDestroyMove(true),
+			},
+			{
+				Config: testAccResourceEntryOnDestroyMove(false),
+				Check: func(*terraform.State) error {
+					cl := testAccClient(t)
+					_, err := cl.ReadEntryByDN(context.Background(), movedDn, "("+dummyFilter+")", &[]string{"1.1"})
+					if err != nil {
+						return fmt.Errorf("expected the entry to be moved to '%s': %w", movedDn, err)
+					}
+					_, err = cl.ReadEntryByDN(context.Background(), "uid=moved01,ou=users,dc=example,dc=com", "("+dummyFilter+")", &[]string{"1.1"})
+					if !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+						return fmt.Errorf("expected the entry to be moved away, got %v", err)
+					}
+					return nil
+				},
+			},
+			{
+				Config: testAccResourceEntryOnDestroyMove(true),
+			},
+			{
+				// the container already has an entry with the RDN, the time of the move is appended to it
+				Config: testAccResourceEntryOnDestroyMove(false),
+				Check: func(*terraform.State) error {
+					movedEntries, err := testAccClient(t).ReadEntriesByFilter(context.Background(), "ou=deleted,dc=example,dc=com", "(uid=moved01*)", &[]string{"1.1"}, 0, ldap.ScopeSingleLevel)
+					if err != nil {
+						return err
+					}
+					if len(*movedEntries) != 2 {
+						return fmt.Errorf("expected both entries to be moved to 'ou=deleted,dc=example,dc=com', got %v", *movedEntries)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func testAccResourceEntryOnDestroyMove(withEntry bool) string {
+	config := `
+resource "ldap_entry" "users_example_com" {
+  dn = "ou=users,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "deleted_example_com" {
+  dn             = "ou=deleted,dc=example,dc=com"
+  delete_subtree = true
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+`
+	if withEntry {
+		config += `
+resource "ldap_entry" "user_moved" {
+  dn                = "uid=moved01,${ldap_entry.users_example_com.dn}"
+  on_destroy        = "move"
+  deleted_container = ldap_entry.deleted_example_com.dn
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    sn          = ["Moved"]
+    cn          = ["Moved 01"]
+  })
+}
+`
+	}
+	return config
+}
+
+func TestAccResourceLdapEntryAttributeNameAliases(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceEntryAttributeNameAliases("commonName", "surname"),
+				Check: resource.TestCheckResourceAttrWith(
+					"ldap_entry.user_alias",
+					"data_json",
+					func(value string) error {
+						var e client.LdapEntry
+						if err := json.Unmarshal([]byte(value), &e.Entry); err != nil {
+							return err
+						}
+						if len(e.Entry["commonName"]) != 1 || len(e.Entry["surname"]) != 1 {
+							return fmt.Errorf("expected the attribute names of the configuration, got %v", e.Entry)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// the names of the server, aliases and names in other cases denote the same attributes
+				Config:   testAccResourceEntryAttributeNameAliases("cn", "sn"),
+				PlanOnly: true,
+			},
+			{
+				Config:   testAccResourceEntryAttributeNameAliases("CN", "SurName"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccResourceEntryAttributeNameAliases(cn string, sn string) string {
+	return fmt.Sprintf(`
+resource "ldap_entry" "users_example_com" {
+  dn = "ou=users,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "user_alias" {
+  dn = "uid=alias01,${ldap_entry.users_example_com.dn}"
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    %s          = ["Alias"]
+    %s          = ["Alias 01"]
+  })
+}
+`, sn, cn)
+}
@@ -0,0 +1,288 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/l-with/terraform-provider-ldap/client"
+)
+
+func resourceLDAPGroup() *schema.Resource {
+	return &schema.Resource{
+		ReadContext:   resourceLDAPGroupRead,
+		CreateContext: resourceLDAPGroupCreate,
+		UpdateContext: resourceLDAPGroupUpdate,
+		DeleteContext: resourceLDAPGroupDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceLDAPGroupImport,
+		},
+
+		CustomizeDiff: resourceLDAPGroupCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			attributeNameDn: {
+				Description: "DN of the LDAP group, changing it renames or moves the group (ModifyDN)",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			attributeNameObjectClass: {
+				Description:      "object class of the LDAP group, `" + objectClassGroupOfNames + "` (members in `member`), `" + objectClassGroupOfUniqueNames + "` (members in `uniqueMember`) or `" + objectClassPosixGroup + "` (members in `memberUid`). Defaults to `" + objectClassGroupOfNames + "`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          objectClassGroupOfNames,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{objectClassGroupOfNames, objectClassGroupOfUniqueNames, objectClassPosixGroup}, false)),
+			},
+			attributeNameMembers: {
+				Description: "the members of the LDAP group, DNs for `" + objectClassGroupOfNames + "` and `" + objectClassGroupOfUniqueNames + "`, user names for `" + objectClassPosixGroup + "` (`" + objectClassGroupOfNames + "` and `" + objectClassGroupOfUniqueNames + "` require at least one member by the standard schema)",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			attributeNameMembershipMode: {
+				Description:      "how the members are managed, `" + membershipModeAuthoritative + "` (members added outside of terraform are removed) or `" + membershipModeAdditive + "` (only the configured members are added and removed, other members are kept). Defaults to `" + membershipModeAuthoritative + "`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          membershipModeAuthoritative,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{membershipModeAuthoritative, membershipModeAdditive}, false)),
+			},
+			attributeNameGidNumber: {
+				Description: "the group ID (`gidNumber`), required for `" + objectClassPosixGroup + "`",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			attributeNameDescription: {
+				Description: "the description of the LDAP group, groups with more than one description are refused",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPGroupCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	objectClass := d.Get(attributeNameObjectClass).(string)
+	// the raw configuration is checked, as 0 is a valid gidNumber
+	rawConfig := d.GetRawConfig()
+	gidNumberSet := rawConfig.IsKnown() && !rawConfig.IsNull() && !rawConfig.GetAttr(attributeNameGidNumber).IsNull()
+	if objectClass == objectClassPosixGroup && !gidNumberSet {
+		return fmt.Errorf("%s is required for %s", attributeNameGidNumber, objectClassPosixGroup)
+	}
+	if objectClass != objectClassPosixGroup && gidNumberSet {
+		return fmt.Errorf("%s is only supported for %s", attributeNameGidNumber, objectClassPosixGroup)
+	}
+	return nil
+}
+
+func resourceLDAPGroupImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceLDAPGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cl := m.(*client.Client)
+
+	dn := d.Id()
+
+	attributes := []string{"objectClass", "gidNumber", "description"}
+	for _, memberAttributeName := range groupMemberAttributeNames {
+		attributes = append(attributes, memberAttributeName)
+	}
+	ldapEntry, err := cl.ReadEntryByDN(ctx, dn, "("+dummyFilter+")", &attributes)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	entry := lowerAttributeNames(ldapEntry.Entry, nil)
+
+	// the configured object class is kept, an imported group gets the first object class of a group found
+	objectClass := d.Get(attributeNameObjectClass).(string)
+	if !containsFold(entry["objectclass"], objectClass) {
+		objectClass = ""
+		for _, groupObjectClass := range []string{objectClassGroupOfNames, objectClassGroupOfUniqueNames, objectClassPosixGroup} {
+			if containsFold(entry["objectclass"], groupObjectClass) {
+				objectClass = groupObjectClass
+				break
+			}
+		}
+	}
+	if objectClass == "" {
+		return diag.Errorf("the entry '%s' is not a group, its object classes are %v", dn, entry["objectclass"])
+	}
+	memberAttributeName := groupMemberAttributeNames[objectClass]
+
+	var configuredMembers []string
+	for _, member := range d.Get(attributeNameMembers).(*schema.Set).List() {
+		configuredMembers = append(configuredMembers, member.(string))
+	}
+	members, otherMembers := matchValues(entry[strings.ToLower(memberAttributeName)], configuredMembers, valueEqualFunc(ctx, cl, memberAttributeName))
+	if d.Get(attributeNameMembershipMode).(string) != membershipModeAdditive {
+		members = append(members, otherMembers...)
+	} else if len(otherMembers) > 0 {
+		tflog.Debug(ctx, "ignoring members not managed by the group", map[string]interface{}{"dn": dn, "members": otherMembers})
+	}
+
+	var gidNumber interface{}
+	if len(entry["gidnumber"]) > 0 {
+		gidNumber, err = strconv.Atoi(entry["gidnumber"][0])
+		if err != nil {
+			return diag.Errorf("the gidNumber '%s' of the group '%s' is not a number: %s", entry["gidnumber"][0], dn, err)
+		}
+	}
+	// description is multi-valued in the standard schema, but only one is managed
+	if len(entry["description"]) > 1 {
+		return diag.Errorf("the group '%s' has %d descriptions, but only one is supported, manage it with ldap_entry instead", dn, len(entry["description"]))
+	}
+	description := ""
+	if len(entry["description"]) > 0 {
+		description = entry["description"][0]
+	}
+
+	d.Set(attributeNameDn, dn)
+	d.Set(attributeNameObjectClass, objectClass)
+	if d.Get(attributeNameMembershipMode).(string) == "" {
+		d.Set(attributeNameMembershipMode, membershipModeAuthoritative)
+	}
+	err = d.Set(attributeNameMembers, members)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set(attributeNameGidNumber, gidNumber)
+	d.Set(attributeNameDescription, description)
+
+	return nil
+}
+
+func resourceLDAPGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cl := m.(*client.Client)
+
+	dn := d.Get(attributeNameDn).(string)
+	objectClass := d.Get(attributeNameObjectClass).(string)
+
+	parsedDn, err := ldap.ParseDN(dn)
+	if err != nil {
+		return diag.Errorf("the DN '%s' of the group is invalid: %s", dn, err)
+	}
+	if len(parsedDn.RDNs) == 0 {
+		return diag.Errorf("the DN of the group is empty")
+	}
+
+	ldapEntry := client.LdapEntry{
+		Dn: dn,
+		Entry: map[string][]string{
+			"objectClass": {objectClass},
+		},
+	}
+	// the values of the RDN are part of the entry
+	for _, rdnAttribute := range parsedDn.RDNs[0].Attributes {
+		ldapEntry.Entry[rdnAttribute.Type] = append(ldapEntry.Entry[rdnAttribute.Type], rdnAttribute.Value)
+	}
+	var members []string
+	for _, member := range d.Get(attributeNameMembers).(*schema.Set).List() {
+		members = append(members, member.(string))
+	}
+	if len(members) > 0 {
+		ldapEntry.Entry[groupMemberAttributeNames[objectClass]] = members
+	}
+	if rawConfig := d.GetRawConfig(); !rawConfig.IsNull() && !rawConfig.GetAttr(attributeNameGidNumber).IsNull() {
+		ldapEntry.Entry["gidNumber"] = []string{strconv.Itoa(d.Get(attributeNameGidNumber).(int))}
+	}
+	if description, ok := d.GetOk(attributeNameDescription); ok {
+		ldapEntry.Entry["description"] = []string{description.(string)}
+	}
+
+	err = cl.CreateEntry(ctx, &ldapEntry)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(dn)
+
+	return resourceLDAPGroupRead(ctx, d, m)
+}
+
+func resourceLDAPGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cl := m.(*client.Client)
+
+	dn := d.Get(attributeNameDn).(string)
+
+	if d.HasChange(attributeNameDn) {
+		oldDn, _ := d.GetChange(attributeNameDn)
+		err := cl.RenameEntry(ctx, oldDn.(string), dn)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.SetId(dn)
+	}
+
+	if d.HasChange(attributeNameMembers) {
+		// only the added and removed members are modified, so that members added outside of terraform are kept in the additive mode
+		oldMembers, newMembers := d.GetChange(attributeNameMembers)
+		var addedMembers, deletedMembers []string
+		for _, member := range newMembers.(*schema.Set).Difference(oldMembers.(*schema.Set)).List() {
+			addedMembers = append(addedMembers, member.(string))
+		}
+		for _, member := range oldMembers.(*schema.Set).Difference(newMembers.(*schema.Set)).List() {
+			deletedMembers = append(deletedMembers, member.(string))
+		}
+		memberAttributeName := groupMemberAttributeNames[d.Get(attributeNameObjectClass).(string)]
+		err := cl.ModifyAttributeValues(ctx, dn, memberAttributeName, addedMembers, deletedMembers)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChanges(attributeNameGidNumber, attributeNameDescription) {
+		ldapEntryOld := client.LdapEntry{Dn: dn, Entry: map[string][]string{}}
+		ldapEntryNew := client.LdapEntry{Dn: dn, Entry: map[string][]string{}}
+		deletedAttributeNameSet := schema.NewSet(schema.HashString, []interface{}{})
+		addedAttributeNameSet := schema.NewSet(schema.HashString, []interface{}{})
+		changedAttributeNameSet := schema.NewSet(schema.HashString, []interface{}{})
+		for attributeName, ldapAttributeName := range map[string]string{attributeNameGidNumber: "gidNumber", attributeNameDescription: "description"} {
+			if !d.HasChange(attributeName) {
+				continue
+			}
+			oldValue, newValue := d.GetChange(attributeName)
+			// gid_number is only changed, as it is required for posixGroup and object_class forces a new group
+			oldString, newString := fmt.Sprint(oldValue), fmt.Sprint(newValue)
+			ldapEntryOld.Entry[ldapAttributeName] = []string{oldString}
+			ldapEntryNew.Entry[ldapAttributeName] = []string{newString}
+			switch {
+			case newString == "":
+				deletedAttributeNameSet.Add(ldapAttributeName)
+			case oldString == "":
+				addedAttributeNameSet.Add(ldapAttributeName)
+			default:
+				changedAttributeNameSet.Add(ldapAttributeName)
+			}
+		}
+		err := cl.UpdateEntry(ctx, &ldapEntryOld, &ldapEntryNew, deletedAttributeNameSet, addedAttributeNameSet, changedAttributeNameSet, client.ModifyStrategyReplace)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceLDAPGroupRead(ctx, d, m)
+}
+
+func resourceLDAPGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cl := m.(*client.Client)
+
+	err := cl.DeleteEntry(ctx, d.Get(attributeNameDn).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/l-with/terraform-provider-ldap/client"
+)
+
+// groupMemberIdSeparator separates the DN of the group from the member in the ID of ldap_group_member.
+const groupMemberIdSeparator = "|"
+
+func resourceLDAPGroupMember() *schema.Resource {
+	return &schema.Resource{
+		ReadContext:   resourceLDAPGroupMemberRead,
+		CreateContext: resourceLDAPGroupMemberCreate,
+		DeleteContext: resourceLDAPGroupMemberDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceLDAPGroupMemberImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			attributeNameGroupDn: {
+				Description: "DN of the LDAP group",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			attributeNameMember: {
+				Description: "the member of the LDAP group, a DN for `" + objectClassGroupOfNames + "` and `" + objectClassGroupOfUniqueNames + "`, a user name for `" + objectClassPosixGroup + "`",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			attributeNameMemberAttribute: {
+				Description: "the attribute holding the members of the LDAP group, defaults to the attribute of the object class of the group (`member`, `uniqueMember` or `memberUid`)",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPGroupMemberImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	groupDn, member, found := strings.Cut(d.Id(), groupMemberIdSeparator)
+	if !found || groupDn == "" || member == "" {
+		return nil, fmt.Errorf("the ID '%s' is not of the form <group_dn>%s<member>", d.Id(), groupMemberIdSeparator)
+	}
+	d.Set(attributeNameGroupDn, groupDn)
+	d.Set(attributeNameMember, member)
+	return []*schema.ResourceData{d}, nil
+}
+
+// groupMemberAttributeName returns the configured attribute holding the members of the group,
+// otherwise the attribute of the object class of the group.
+func groupMemberAttributeName(ctx context.Context, d *schema.ResourceData, cl *client.Client) (string, error) {
+	if memberAttributeName := d.Get(attributeNameMemberAttribute).(string); memberAttributeName != "" {
+		return memberAttributeName, nil
+	}
+
+	groupDn := d.Get(attributeNameGroupDn).(string)
+	ldapEntry, err := cl.ReadEntryByDN(ctx, groupDn, "("+dummyFilter+")", &[]string{"objectClass"})
+	if err != nil {
+		return "", err
+	}
+	objectClasses := lowerAttributeNames(ldapEntry.Entry, nil)["objectclass"]
+	for _, objectClass := range []string{objectClassGroupOfNames, objectClassGroupOfUniqueNames, objectClassPosixGroup} {
+		if containsFold(objectClasses, objectClass) {
+			return groupMemberAttributeNames[objectClass], nil
+		}
+	}
+	return "", fmt.Errorf("the entry '%s' is not a group, its object classes are %v, %s has to be specified", groupDn, objectClasses, attributeNameMemberAttribute)
+}
+
+func resourceLDAPGroupMemberRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cl := m.(*client.Client)
+
+	groupDn := d.Get(attributeNameGroupDn).(string)
+	member := d.Get(attributeNameMember).(string)
+
+	memberAttributeName, err := groupMemberAttributeName(ctx, d, cl)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	present, err := cl.CompareAttributeValue(ctx, groupDn, memberAttributeName, member)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !present {
+		tflog.Info(ctx, "member was removed from the group", map[string]interface{}{"group_dn": groupDn, "member": member})
+		d.SetId("")
+		return nil
+	}
+
+	d.Set(attributeNameMemberAttribute, memberAttributeName)
+
+	return nil
+}
+
+func resourceLDAPGroupMemberCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cl := m.(*client.Client)
+
+	groupDn := d.Get(attributeNameGroupDn).(string)
+	member := d.Get(attributeNameMember).(string)
+
+	memberAttributeName, err := groupMemberAttributeName(ctx, d, cl)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = cl.ModifyAttributeValues(ctx, groupDn, memberAttributeName, []string{member}, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(groupDn + groupMemberIdSeparator + member)
+	d.Set(attributeNameMemberAttribute, memberAttributeName)
+
+	return resourceLDAPGroupMemberRead(ctx, d, m)
+}
+
+func resourceLDAPGroupMemberDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cl := m.(*client.Client)
+
+	groupDn := d.Get(attributeNameGroupDn).(string)
+
+	err := cl.ModifyAttributeValues(ctx, groupDn, d.Get(attributeNameMemberAttribute).(string), nil, []string{d.Get(attributeNameMember).(string)})
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceLdapGroupMember(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceGroupMember(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group_member.member", "id", "cn=membergroup01,ou=groups,dc=example,dc=com|uid=member02,ou=users,dc=example,dc=com"),
+					resource.TestCheckResourceAttr("ldap_group_member.member", "member_attribute", "member"),
+					resource.TestCheckResourceAttr("ldap_group.group", "members.#", "1"),
+					resource.TestCheckResourceAttr("data.ldap_entries.group_members", "entries.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "ldap_group_member.member",
+				ImportState:       true,
+				ImportStateId:     "cn=membergroup01,ou=groups,dc=example,dc=com|uid=member02,ou=users,dc=example,dc=com",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceGroupMember() string {
+	return `
+resource "ldap_entry" "users_example_com" {
+  dn = "ou=users,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "groups_example_com" {
+  dn = "ou=groups,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "user_member01" {
+  dn = "uid=member01,${ldap_entry.users_example_com.dn}"
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    sn          = ["Member"]
+    cn          = ["Member 01"]
+  })
+}
+
+resource "ldap_entry" "user_member02" {
+  dn = "uid=member02,${ldap_entry.users_example_com.dn}"
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    sn          = ["Member"]
+    cn          = ["Member 02"]
+  })
+}
+
+resource "ldap_group" "group" {
+  dn              = "cn=membergroup01,${ldap_entry.groups_example_com.dn}"
+  members         = [ldap_entry.user_member01.dn]
+  membership_mode = "additive"
+}
+
+resource "ldap_group_member" "member" {
+  group_dn = ldap_group.group.dn
+  member   = ldap_entry.user_member02.dn
+}
+
+data "ldap_entries" "group_members" {
+  depends_on = [ldap_group_member.member]
+  ou         = ldap_entry.groups_example_com.dn
+  filter     = "&(member=${ldap_entry.user_member01.dn})(member=${ldap_entry.user_member02.dn})"
+}
+`
+}
@@ -0,0 +1,157 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceLdapGroup(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceGroup(membershipModeAuthoritative, `ldap_entry.user_group01.dn, ldap_entry.user_group02.dn`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group.group", "id", "cn=group01,ou=groups,dc=example,dc=com"),
+					resource.TestCheckResourceAttr("ldap_group.group", "object_class", "groupOfNames"),
+					resource.TestCheckResourceAttr("ldap_group.group", "members.#", "2"),
+					resource.TestCheckResourceAttr("ldap_group.posix_group", "members.#", "2"),
+					resource.TestCheckResourceAttr("ldap_group.posix_group", "gid_number", "10001"),
+				),
+			},
+			{
+				Config: testAccResourceGroup(membershipModeAdditive, `ldap_entry.user_group02.dn`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group.group", "members.#", "1"),
+					resource.TestCheckTypeSetElemAttr("ldap_group.group", "members.*", "uid=group02,ou=users,dc=example,dc=com"),
+					resource.TestCheckResourceAttr("data.ldap_entries.group_members", "entries.#", "0"),
+				),
+			},
+			{
+				ResourceName:            "ldap_group.posix_group",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"membership_mode"},
+			},
+		},
+	})
+}
+
+func testAccResourceGroup(membershipMode string, members string) string {
+	return fmt.Sprintf(`
+resource "ldap_entry" "users_example_com" {
+  dn = "ou=users,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "groups_example_com" {
+  dn = "ou=groups,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_entry" "user_group01" {
+  dn = "uid=group01,${ldap_entry.users_example_com.dn}"
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    sn          = ["Group"]
+    cn          = ["Group 01"]
+  })
+}
+
+resource "ldap_entry" "user_group02" {
+  dn = "uid=group02,${ldap_entry.users_example_com.dn}"
+  data_json = jsonencode({
+    objectClass = ["inetOrgPerson"]
+    sn          = ["Group"]
+    cn          = ["Group 02"]
+  })
+}
+
+resource "ldap_group" "group" {
+  dn              = "cn=group01,${ldap_entry.groups_example_com.dn}"
+  members         = [%s]
+  membership_mode = "%s"
+  description     = "group managed by terraform"
+}
+
+resource "ldap_group" "posix_group" {
+  dn           = "cn=posixgroup01,${ldap_entry.groups_example_com.dn}"
+  object_class = "posixGroup"
+  gid_number   = 10001
+  members      = ["group01", "group02"]
+}
+
+data "ldap_entries" "group_members" {
+  depends_on = [ldap_group.group]
+  ou         = ldap_entry.groups_example_com.dn
+  filter     = "member=${ldap_entry.user_group01.dn}"
+}
+`, members, membershipMode)
+}
+
+func TestAccResourceLdapGroupGidNumberZeroAndDescriptions(t *testing.T) {
+	dn := "cn=posixgroup02,ou=posixgroups,dc=example,dc=com"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceGroupGidNumberZero(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group.posix_group", "gid_number", "0"),
+					resource.TestCheckResourceAttr("ldap_group.posix_group", "description", "group with gidNumber 0"),
+				),
+			},
+			{
+				// a second description added outside of terraform is not dropped silently
+				PreConfig: func() {
+					err := testAccClient(t).ModifyAttributeValues(context.Background(), dn, "description", []string{"second description"}, nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:      testAccResourceGroupGidNumberZero(),
+				ExpectError: regexp.MustCompile("only one is supported"),
+			},
+			{
+				PreConfig: func() {
+					err := testAccClient(t).ModifyAttributeValues(context.Background(), dn, "description", nil, []string{"second description"})
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccResourceGroupGidNumberZero(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group.posix_group", "gid_number", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceGroupGidNumberZero() string {
+	return `
+resource "ldap_entry" "posixgroups_example_com" {
+  dn = "ou=posixgroups,dc=example,dc=com"
+  data_json = jsonencode({
+    objectClass = ["organizationalUnit"]
+  })
+}
+
+resource "ldap_group" "posix_group" {
+  dn           = "cn=posixgroup02,${ldap_entry.posixgroups_example_com.dn}"
+  object_class = "posixGroup"
+  gid_number   = 0
+  description  = "group with gidNumber 0"
+}
+`
+}